@@ -0,0 +1,117 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Alias defines the type ptrToAlias points to as an alias of the type
+// ptrToTarget points to. Resolving the alias resolves the target, sharing
+// the target's Lifetime and cached value. Use the result as the Constructor
+// of a Def:
+//
+//	defs := []*di.Def{
+//		{NewService, di.Singleton},
+//		{di.Alias((*Reader)(nil), (*Service)(nil)), di.Singleton},
+//		{di.Alias((*Writer)(nil), (*Service)(nil)), di.Singleton},
+//	}
+//
+// The target must be assignable to the alias. The Lifetime of the Def is
+// not used, an alias is always resolved according to the Lifetime of its
+// target. The alias may be combined with Named, and the target may be
+// selected by name with Params:
+//
+//	di.Named("replica", di.Params(di.Alias((*Reader)(nil), (*DB)(nil)), "name=replica"))
+func Alias(ptrToAlias interface{}, ptrToTarget interface{}) interface{} {
+	aliasType, err := ptrElem("Alias", ptrToAlias)
+	if err != nil {
+		return &annotatedFn{Err: err}
+	}
+
+	targetType, err := ptrElem("Alias", ptrToTarget)
+	if err != nil {
+		return &annotatedFn{Err: err}
+	}
+
+	annotated, err := newAliasFn(aliasType, targetType)
+	if err != nil {
+		return &annotatedFn{Err: err}
+	}
+
+	return annotated
+}
+
+// As defines the value returned by constructor as each type ptrsToAlias
+// point to, in addition to its own type. It is a shortcut for defining
+// the constructor and then an Alias for each type. If the constructor is
+// Named each alias has the same name:
+//
+//	def := &di.Def{di.As(NewService, (*Reader)(nil), (*Writer)(nil)), di.Singleton}
+func As(constructor interface{}, ptrsToAlias ...interface{}) interface{} {
+	return annotate(constructor, func(af *annotatedFn) error {
+		for _, ptrToAlias := range ptrsToAlias {
+			aliasType, err := ptrElem("As", ptrToAlias)
+
+			if err != nil {
+				return err
+			}
+
+			af.As = append(af.As[:len(af.As):len(af.As)], aliasType)
+		}
+
+		return nil
+	})
+}
+
+// newAliasFn returns an annotated func which returns its only parameter,
+// of type targetType, as aliasType
+func newAliasFn(aliasType, targetType reflect.Type) (*annotatedFn, error) {
+	if targetType.AssignableTo(aliasType) == false {
+		return nil, fmt.Errorf("di: %v cannot be an alias of %v", aliasType, targetType)
+	}
+
+	fnType := reflect.FuncOf([]reflect.Type{targetType}, []reflect.Type{aliasType}, false)
+	fn := reflect.MakeFunc(fnType, func(ins []reflect.Value) []reflect.Value {
+		value := reflect.New(aliasType).Elem()
+		value.Set(ins[0])
+		return []reflect.Value{value}
+	})
+
+	return &annotatedFn{
+		Alias: true,
+		Fn:    fn,
+	}, nil
+}
+
+// newAsFns returns an Alias for each of the As types of annotated
+func newAsFns(annotated *annotatedFn) ([]*annotatedFn, error) {
+	targetType := annotated.Fn.Type().Out(0)
+	aliases := make([]*annotatedFn, len(annotated.As))
+
+	for index, aliasType := range annotated.As {
+		alias, err := newAliasFn(aliasType, targetType)
+
+		if err != nil {
+			return nil, err
+		}
+
+		alias.Name = annotated.Name
+		alias.Parent = annotated
+		alias.Params = []*paramTag{{Name: annotated.Name}}
+		aliases[index] = alias
+	}
+
+	return aliases, nil
+}
+
+// ptrElem returns the type ptr points to, or an error if ptr is not
+// a pointer. fnName is the name of the func ptr was passed to
+func ptrElem(fnName string, ptr interface{}) (reflect.Type, error) {
+	ptrType := reflect.TypeOf(ptr)
+
+	if ptrType == nil || ptrType.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("di: %v: argument must be a pointer type: %v", fnName, ptrType)
+	}
+
+	return ptrType.Elem(), nil
+}
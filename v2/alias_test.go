@@ -0,0 +1,131 @@
+package di
+
+import "testing"
+
+type service struct {
+	id int
+}
+
+func (s *service) A() int        { return s.id }
+func (s *service) B() (int, int) { return s.id, s.id }
+func (s *service) Plugin() int   { return s.id }
+
+func TestAlias(t *testing.T) {
+	serviceCount := 0
+	newService := func() *service {
+		serviceCount += 1
+		return &service{serviceCount}
+	}
+
+	t.Run("Alias", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{newService, PerResolve},
+			{Alias((*A)(nil), (**service)(nil)), Singleton},
+			{Alias((*B)(nil), (**service)(nil)), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resolveErr := resolver.Invoke(func(a A, b B, s *service) {
+			b1, _ := b.B()
+			if a.A() != b1 || a != s {
+				t.Fatal("expecting aliases to share the target")
+			}
+		})
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		var a A
+		resolveErr = resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a.A() != 2 {
+			t.Fatal("expecting the alias to follow the lifetime of its target", a.A())
+		}
+
+		for _, info := range resolver.Definitions() {
+			if info.Lifetime != PerResolve {
+				t.Fatal(info.Type, info.Lifetime)
+			}
+		}
+	})
+	t.Run("As", func(t *testing.T) {
+		serviceCount = 0
+		resolver, err := resolverChildNew([]*Def{
+			{Named("one", As(newService, (*A)(nil), (*Plugin)(nil))), Singleton},
+			{Group(newService), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resolveErr := resolver.Invoke(Params(func(a A, p Plugin, s *service) {
+			if a != s || p != s || serviceCount != 1 {
+				t.Fatal("expecting every type to share the same singleton")
+			}
+		}, "name=one", "name=one", "name=one"))
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		infos := resolver.Definitions()
+		if len(infos) != 4 {
+			t.Fatal(len(infos))
+		}
+	})
+	t.Run("Named", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Named("one", newService), Singleton},
+			{Named("two", Params(Alias((*A)(nil), (**service)(nil)), "name=one")), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.ResolveNamed("two", &a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		alias := &Def{Alias((*A)(nil), (**service)(nil)), Singleton}
+		_, err := resolverChildNew([]*Def{{newService, Singleton}, alias, alias})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = resolverChildNew([]*Def{{As(newService, (*A)(nil)), Singleton}, {NewA, Singleton}})
+		if err == nil {
+			t.Fatal("expecting duplicate definition err")
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		invalid := []interface{}{
+			Alias((*A)(nil), (*B)(nil)),
+			Alias(nil, (**service)(nil)),
+			Alias((*A)(nil), nil),
+			As(newService, (*E)(nil), (*C)(nil), 4),
+			As(newService, (*pluginImpl)(nil)),
+			Group(As(newService, (*A)(nil))),
+		}
+
+		for _, constructor := range invalid {
+			_, err := resolverChildNew([]*Def{{newService, Singleton}, {constructor, Singleton}})
+
+			if err == nil {
+				t.Fatal("expecting invalid alias err")
+			}
+		}
+	})
+}
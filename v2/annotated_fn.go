@@ -0,0 +1,194 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// annotatedFn is a func which has been annotated with additional
+// information about how it should be registered as a definition, or
+// about how its parameters should be injected
+type annotatedFn struct {
+	// Alias indicates Fn returns its only parameter as another type.
+	// See Alias
+	Alias bool
+
+	// As are the additional types the return value of Fn is defined
+	// as. See As
+	As []reflect.Type
+
+	// Condition decides whether Fn is added to a resolver. See When
+	Condition *Condition
+
+	// Config is the configuration struct Fn returns, if Fn was created
+	// for a configuration definition. See Config
+	Config *configDef
+
+	// Decorator indicates Fn decorates an existing definition of the
+	// type it returns. See Decorate
+	Decorator bool
+
+	// Err is any error encountered while annotating the func. It is
+	// reported once the annotated func is added to a resolver
+	Err error
+
+	// Factory is the constructor of the factory Fn returns, if Fn was
+	// created for a factory definition. See Factory
+	Factory reflect.Value
+
+	// Field is the field of a result object Fn returns, if Fn was
+	// created for one of the fields of a result object. See Out
+	Field *resultField
+
+	// Fn is the func being annotated
+	Fn reflect.Value
+
+	// Instance is the pre-built value returned by Fn, if Fn was created
+	// for an instance definition. See Instance
+	Instance reflect.Value
+
+	// Group indicates the return value of Fn is a member of a group
+	// of definitions. See Group
+	Group bool
+
+	// Keyed indicates the return value of Fn is a member of a map
+	// of definitions under MapKey. See Keyed
+	Keyed bool
+
+	// MapKey is the key of the return value of Fn in a map of
+	// definitions. Only valid if Keyed is true
+	MapKey string
+
+	// Module is the module Fn is defined in, if any. See Module
+	Module *Module
+
+	// Name is the name the return value of Fn is registered under
+	Name string
+
+	// Override indicates Fn replaces any earlier definition of the type
+	// it returns. See Override
+	Override bool
+
+	// Parent is the annotated func this func was derived from, such as
+	// the constructor of a result object for one of its fields. Derived
+	// funcs are defined along with their parent
+	Parent *annotatedFn
+
+	// Params are the injection options for each parameter of Fn, by
+	// index. May be shorter than the number of parameters of Fn
+	Params []*paramTag
+}
+
+// newAnnotatedFn returns a copy of fn if it is already an *annotatedFn,
+// otherwise a new *annotatedFn wrapping fn is returned. An error is
+// returned if fn is not a func or was annotated incorrectly
+func newAnnotatedFn(fn interface{}) (*annotatedFn, error) {
+	existing, isAnnotated := fn.(*annotatedFn)
+	if isAnnotated {
+		if existing.Err != nil {
+			return nil, existing.Err
+		}
+
+		annotated := *existing
+		return &annotated, nil
+	}
+
+	fnValue := reflect.ValueOf(fn)
+	err := verifyFn(fnValue)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &annotatedFn{Fn: fnValue}, nil
+}
+
+// annotate wraps fn in an *annotatedFn, and then calls fn2 on it
+// to apply additional annotations. Any error is kept with the returned
+// value until it is added to a resolver
+func annotate(fn interface{}, fn2 func(*annotatedFn) error) interface{} {
+	annotated, err := newAnnotatedFn(fn)
+
+	if err != nil {
+		return &annotatedFn{Err: err}
+	}
+
+	err = fn2(annotated)
+	if err != nil {
+		annotated.Err = err
+	}
+
+	return annotated
+}
+
+// GroupKey returns the key of the group or map of definitions the
+// return value of Fn is a member of. key is the key of the return value
+func (af *annotatedFn) GroupKey(key depKey) depKey {
+	if af.Keyed {
+		return newDepKey(reflect.MapOf(stringType, key.Type), key.Name)
+	}
+
+	return newDepKey(reflect.SliceOf(key.Type), key.Name)
+}
+
+// IsInstance returns true if the annotated func returns a pre-built
+// value. See Instance
+func (af *annotatedFn) IsInstance() bool {
+	return af.Instance.IsValid()
+}
+
+// SameInstance returns true if neither func returns a pre-built value,
+// or if both funcs return the exact same pre-built value
+func (af *annotatedFn) SameInstance(af2 *annotatedFn) bool {
+	if af.IsInstance() == false || af2.IsInstance() == false {
+		return af.IsInstance() == af2.IsInstance()
+	}
+
+	return sameInstance(af.Instance, af2.Instance)
+}
+
+// Root returns the annotated func af was derived from, or af if it was
+// not derived from another func. See Parent
+func (af *annotatedFn) Root() *annotatedFn {
+	for af.Parent != nil {
+		af = af.Parent
+	}
+
+	return af
+}
+
+// String returns a string describing the annotated func, followed by the
+// Condition of the func if it has one
+func (af *annotatedFn) String() string {
+	if af.Condition != nil {
+		return fmt.Sprintf("%v when %v", af.fnString(), af.Condition)
+	}
+
+	return af.fnString()
+}
+
+// fnString returns a string describing the annotated func
+func (af *annotatedFn) fnString() string {
+	if af.Field != nil {
+		return af.Field.String()
+	}
+
+	if af.Alias {
+		fnType := af.Fn.Type()
+		return fmt.Sprintf("alias(%v => %v)", fnType.Out(0), fnType.In(0))
+	}
+
+	if af.IsInstance() {
+		return fmt.Sprintf("%#v", af.Instance)
+	}
+
+	if af.Config != nil {
+		return fmt.Sprintf("config(%v %p)", af.Config.Type, af.Config)
+	}
+
+	if af.Factory.IsValid() {
+		return fmt.Sprintf("factory(%v => %#v)", af.Fn.Type().Out(0), af.Factory)
+	}
+
+	return fmt.Sprintf("%#v", af.Fn)
+}
@@ -0,0 +1,94 @@
+package di
+
+import (
+	"fmt"
+	"strings"
+)
+
+// verifyCaptives returns an error if a Singleton definition depends on a
+// definition with a shorter Lifetime, or on the http request, as the
+// Singleton would capture the first value it is resolved with forever.
+// PerDependency definitions are followed, as a Singleton captures every
+// PerDependency value it depends on. A provider or an IResolver captures
+// the resolver the Singleton was created by, so the targets of providers
+// are checked in the same way, and an IResolver is always captive.
+// Dependencies tagged as captive, see Params, are not checked
+func verifyCaptives(deps map[depKey]*depNode) error {
+	for _, node := range allNodes(deps) {
+		if node.Lifetime != Singleton {
+			continue
+		}
+
+		path := captivePath(deps, node, []string{node.TypeName}, map[*depNode]bool{})
+		if path != nil {
+			return fmt.Errorf("di: Singleton %v captures a dependency with a shorter lifetime: %v", node.TypeName, strings.Join(path, "->"))
+		}
+	}
+
+	return nil
+}
+
+// captivePath returns the path from a Singleton to the first value with a
+// shorter Lifetime it would capture through the dependencies of node, or
+// nil if there is no such value. path is the path from the Singleton to
+// node
+func captivePath(deps map[depKey]*depNode, node *depNode, path []string, seen map[*depNode]bool) []string {
+	for _, dep := range node.DependsOn {
+		if dep.Captive {
+			continue
+		}
+
+		if dep.Key.Name == "" && (dep.Key.Type == requestType || dep.Key.Type == responseWriterType || dep.Key.Type == iresolverType) {
+			return append(path[:len(path):len(path)], dep.Key.String())
+		}
+
+		target := dep.Node
+		switch {
+		case target != nil:
+		case dep.Provider != nil:
+			target = deps[dep.Key]
+		default:
+			target = node.Edges[dep.Key]
+		}
+
+		if target == nil {
+			continue
+		}
+
+		captive := captiveTarget(deps, target, path, seen)
+		if captive != nil {
+			return captive
+		}
+	}
+
+	return nil
+}
+
+// captiveTarget returns the path from a Singleton to the first value with
+// a shorter Lifetime it would capture by depending on target, or nil if
+// there is no such value
+func captiveTarget(deps map[depKey]*depNode, target *depNode, path []string, seen map[*depNode]bool) []string {
+	path = append(path[:len(path):len(path)], fmt.Sprintf("%v(%v)", target.TypeName, target.Lifetime))
+
+	switch target.Lifetime {
+	case PerHttpRequest, PerResolve:
+		return path
+	case PerDependency:
+		if seen[target] {
+			return nil
+		}
+
+		seen[target] = true
+		for _, member := range target.Members {
+			captive := captiveTarget(deps, member, path, seen)
+
+			if captive != nil {
+				return captive
+			}
+		}
+
+		return captivePath(deps, target, path, seen)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package di
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCaptive(t *testing.T) {
+	newA := func() A { return &aImpl{1} }
+	newB := func(a A) B { return &bImpl{a.A(), a.A()} }
+	newC := func(B) C { return new(struct{}) }
+	newHttpC := func(*http.Request) C { return new(struct{}) }
+	newPlugins := func(ps []Plugin) Plugins { return &pluginsImpl{ps} }
+	newPlugin := func() Plugin { return &pluginImpl{1} }
+
+	t.Run("Captive", func(t *testing.T) {
+		defsList := [][]*Def{
+			{{newA, PerHttpRequest}, {newB, Singleton}},
+			{{newA, PerResolve}, {newB, PerDependency}, {newC, Singleton}},
+			{{newHttpC, Singleton}},
+			{{Group(newPlugin), PerResolve}, {newPlugins, Singleton}},
+			{{newA, PerResolve}, {As(newB, (*C)(nil)), PerDependency}, {func(C) E { return nil }, Singleton}},
+			{{newA, PerResolve}, {func(a func() (A, error)) B { return nil }, Singleton}},
+			{{newA, PerResolve}, {newB, PerDependency}, {func(b func() (B, error)) C { return nil }, Singleton}},
+			{{func(IResolver) A { return nil }, Singleton}},
+		}
+
+		for index, defs := range defsList {
+			_, err := resolverChildNew(defs)
+
+			if err == nil || strings.Contains(err.Error(), "captures a dependency with a shorter lifetime") == false {
+				t.Fatal(index, err)
+			}
+		}
+
+		_, err := resolverChildNew(defsList[1])
+		if strings.Contains(err.Error(), "di.C->di.B(PerDependency)->di.A(PerResolve)") == false {
+			t.Fatal(err)
+		}
+	})
+	t.Run("Allowed", func(t *testing.T) {
+		defsList := [][]*Def{
+			{{newA, Singleton}, {newB, PerDependency}, {newC, Singleton}},
+			{{newA, PerResolve}, {newB, PerHttpRequest}, {newC, PerResolve}},
+			{{newA, PerHttpRequest}, {Params(newB, "captive"), Singleton}},
+			{{newA, Singleton}, {func(a func() (A, error)) B { return nil }, Singleton}},
+			{{newA, PerResolve}, {Params(func(a func() (A, error)) B { return nil }, "captive"), Singleton}},
+			{{Params(func(IResolver) A { return nil }, "captive"), Singleton}},
+		}
+
+		for index, defs := range defsList {
+			_, err := resolverChildNew(defs)
+
+			if err != nil {
+				t.Fatal(index, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync/atomic"
+)
+
+// ErrClosed is the Err of the *ErrResolve returned when a value is
+// resolved after the resolver was closed. See IHttpResolver.Close
+var ErrClosed = errors.New("di: the resolver is closed")
+
+// ErrClose is returned by Close when some Singletons could not be
+// stopped or disposed of.
+//
+// Implements the error interface
+type ErrClose struct {
+	// Errs contains an error for each Singleton which could not be
+	// stopped or disposed of, in the order they were stopped and disposed
+	// of. If the context passed to Close was done before every Singleton
+	// was disposed of the last error is the error of the context
+	Errs []error
+}
+
+// Error returns an error string describing every error encountered
+func (ec *ErrClose) Error() string {
+	if len(ec.Errs) == 1 {
+		return ec.Errs[0].Error()
+	}
+
+	msgs := make([]string, len(ec.Errs))
+	for index, err := range ec.Errs {
+		msgs[index] = err.Error()
+	}
+
+	return fmt.Sprintf("di: %v errors closing the resolver:\n\t%v", len(ec.Errs), strings.Join(msgs, "\n\t"))
+}
+
+func (c *resolverParent) Close(ctx context.Context) error {
+	if atomic.SwapInt32(&c.closed, 1) == 1 {
+		return nil
+	}
+
+	c.lifecycleLock.Lock()
+	errs := c.stop(ctx)
+	c.lifecycleLock.Unlock()
+
+	nodes := dependencyOrder(c.allDeps)
+	disposed := make(map[interface{}]bool)
+
+	for index := len(nodes) - 1; index >= 0; index -= 1 {
+		node := nodes[index]
+		if node.Lifetime != Singleton || node.Annotated != nil && node.Annotated.IsInstance() {
+			continue
+		}
+
+		cacheValue, hasCacheValue := c.singletons.Get(node)
+		if hasCacheValue == false {
+			continue
+		}
+
+		cacheValue.lock.Lock()
+		value, hasValue := cacheValue.Value()
+		cacheValue.lock.Unlock()
+
+		if hasValue == false || isDisposable(value) == false {
+			continue
+		}
+
+		if value.Type().Comparable() {
+			if disposed[value.Interface()] {
+				continue
+			}
+
+			disposed[value.Interface()] = true
+		}
+
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		err := dispose(ctx, value.Interface())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("di: closing %v: %w", node.TypeName, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ErrClose{errs}
+	}
+
+	return nil
+}
+
+// isClosed returns true if Close has been called on the resolver
+func (c *resolverParent) isClosed() bool {
+	return atomic.LoadInt32(&c.closed) == 1
+}
+
+// isDisposable returns true if value is an IDisposable or an io.Closer
+func isDisposable(value reflect.Value) bool {
+	switch value.Interface().(type) {
+	case IDisposable, io.Closer:
+		return true
+	}
+
+	return false
+}
+
+// dispose disposes of value if it is an IDisposable or an io.Closer,
+// preferring IDisposable if it is both
+func dispose(ctx context.Context, value interface{}) error {
+	switch disposable := value.(type) {
+	case IDisposable:
+		return disposable.Di_Dispose(ctx)
+	case io.Closer:
+		return disposable.Close()
+	}
+
+	return nil
+}
+
+// dependencyOrder returns every node of deps, including the members of
+// groups and the definitions wrapped by decorators, ordered so that each
+// node comes after every node it depends on. Providers count as
+// dependencies. Nodes which do not depend on each other are ordered by
+// type name
+func dependencyOrder(deps map[depKey]*depNode) []*depNode {
+	nodes := make([]*depNode, 0, len(deps))
+	seen := make(map[*depNode]bool, len(deps))
+
+	var visit func(node *depNode)
+	visit = func(node *depNode) {
+		if seen[node] {
+			return
+		}
+
+		seen[node] = true
+		for _, dep := range node.DependsOn {
+			if dep.Provider == nil {
+				continue
+			}
+
+			if target, hasTarget := deps[dep.Key]; hasTarget {
+				visit(target)
+			}
+		}
+
+		for _, child := range node.Children() {
+			visit(child)
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	for _, node := range sortedNodes(deps) {
+		visit(node)
+	}
+
+	return nodes
+}
@@ -0,0 +1,233 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testCloser records its name in closed when it is closed
+type testCloser struct {
+	closed *[]string
+	err    error
+	name   string
+}
+
+func (tc *testCloser) A() int { return 0 }
+
+func (tc *testCloser) Close() error {
+	*tc.closed = append(*tc.closed, tc.name)
+	return tc.err
+}
+
+// testDisposable records its name in closed when it is disposed of
+type testDisposable struct {
+	testCloser
+	ctx context.Context
+}
+
+func (td *testDisposable) Di_Dispose(ctx context.Context) error {
+	td.ctx = ctx
+	*td.closed = append(*td.closed, td.name+".Di_Dispose")
+	return nil
+}
+
+func TestClose(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Order", func(t *testing.T) {
+		closed := []string{}
+		newC := func(D, E) C { return &testCloser{closed: &closed, name: "c"} }
+		newD := func(func() (E, error)) D { return &testCloser{closed: &closed, name: "d"} }
+		newE := func() E { return &testCloser{closed: &closed, name: "e"} }
+
+		resolver, err := resolverChildNew([]*Def{{newE, Singleton}, {newD, PerDependency}, {newC, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var c C
+		if err := resolver.Resolve(&c); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := resolver.Close(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Join(closed, ",") != "c,e" {
+			t.Fatal("expecting Singletons in reverse dependency order", closed)
+		}
+
+		if err := resolver.Close(ctx); err != nil || len(closed) != 2 {
+			t.Fatal("expecting a second Close to do nothing", err, closed)
+		}
+	})
+	t.Run("Provider", func(t *testing.T) {
+		closed := []string{}
+		newC := func(func() (E, error)) C { return &testCloser{closed: &closed, name: "c"} }
+		newE := func() E { return &testCloser{closed: &closed, name: "e"} }
+
+		resolver, err := resolverChildNew([]*Def{{newC, Singleton}, {newE, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var c C
+		var e E
+		if resolver.Resolve(&e) != nil || resolver.Resolve(&c) != nil {
+			t.Fatal("expecting c and e to resolve")
+		}
+
+		if err := resolver.Close(ctx); err != nil || strings.Join(closed, ",") != "c,e" {
+			t.Fatal("expecting providers to count as dependencies", err, closed)
+		}
+	})
+	t.Run("Skipped", func(t *testing.T) {
+		closed := []string{}
+		instance := &testCloser{closed: &closed, name: "instance"}
+		shared := &testCloser{closed: &closed, name: "shared"}
+		newC := func() C { return &testCloser{closed: &closed, name: "c"} }
+
+		resolver, err := resolverChildNew([]*Def{
+			{InstanceAs((*D)(nil), instance), Singleton},
+			{newC, Singleton},
+			{func() E { return shared }, Singleton},
+			{func() A { return shared }, Singleton},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		var d D
+		var e E
+		if resolver.Resolve(&a) != nil || resolver.Resolve(&d) != nil || resolver.Resolve(&e) != nil {
+			t.Fatal("expecting a, d, and e to resolve")
+		}
+
+		if err := resolver.Close(ctx); err != nil || strings.Join(closed, ",") != "shared" {
+			t.Fatal("expecting only created Singletons to be closed, once each", err, closed)
+		}
+	})
+	t.Run("IDisposable", func(t *testing.T) {
+		closed := []string{}
+		disposable := &testDisposable{testCloser: testCloser{closed: &closed, name: "c"}}
+
+		resolver, err := resolverChildNew([]*Def{{func() C { return disposable }, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var c C
+		if err := resolver.Resolve(&c); err != nil {
+			t.Fatal(err)
+		}
+
+		type key struct{}
+		disposeCtx := context.WithValue(ctx, key{}, true)
+		if err := resolver.Close(disposeCtx); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(closed) != 1 || closed[0] != "c.Di_Dispose" || disposable.ctx != disposeCtx {
+			t.Fatal("expecting Di_Dispose to be called with the context", closed)
+		}
+	})
+	t.Run("Errors", func(t *testing.T) {
+		closed := []string{}
+		errC := errors.New("c")
+		errE := errors.New("e")
+		newC := func(E) C { return &testCloser{closed: &closed, err: errC, name: "c"} }
+		newE := func() E { return &testCloser{closed: &closed, err: errE, name: "e"} }
+
+		resolver, err := resolverChildNew([]*Def{{newC, Singleton}, {newE, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var c C
+		if err := resolver.Resolve(&c); err != nil {
+			t.Fatal(err)
+		}
+
+		err = resolver.Close(ctx)
+		var closeErr *ErrClose
+		if errors.As(err, &closeErr) == false || len(closeErr.Errs) != 2 {
+			t.Fatal("expecting every error", err)
+		}
+
+		if errors.Is(closeErr.Errs[0], errC) == false || errors.Is(closeErr.Errs[1], errE) == false {
+			t.Fatal("expecting the errors in the order they were closed", err)
+		}
+
+		if strings.HasPrefix(err.Error(), "di: 2 errors closing the resolver:\n\tdi: closing di.C: c") == false {
+			t.Fatal("unexpected error", err)
+		}
+	})
+	t.Run("Done", func(t *testing.T) {
+		closed := []string{}
+		resolver, err := resolverChildNew([]*Def{{func() C { return &testCloser{closed: &closed, name: "c"} }, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var c C
+		if err := resolver.Resolve(&c); err != nil {
+			t.Fatal(err)
+		}
+
+		doneCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err = resolver.Close(doneCtx)
+		var closeErr *ErrClose
+		if errors.As(err, &closeErr) == false || closeErr.Errs[0] != context.Canceled || len(closed) != 0 {
+			t.Fatal("expecting the error of the context", err, closed)
+		}
+	})
+	t.Run("Resolve", func(t *testing.T) {
+		var errResolve *ErrResolve
+		errFn := func(err *ErrResolve, w http.ResponseWriter, r *http.Request) { errResolve = err }
+		resolver, err := NewResolver(errFn, []*Def{{NewA, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var inner IResolver
+		if err := resolver.Resolve(&inner); err != nil {
+			t.Fatal(err)
+		}
+
+		handler, err := resolver.HttpHandler(func(A) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := resolver.Close(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		_, curryErr := resolver.Curry(func(A) {})
+		errs := []*ErrResolve{
+			resolver.Resolve(&a),
+			resolver.ResolveNamed("name", &a),
+			resolver.Invoke(func(A) {}),
+			curryErr,
+			inner.Resolve(&a),
+		}
+
+		handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+		errs = append(errs, errResolve)
+
+		for index, err := range errs {
+			if err == nil || err.Err != ErrClosed {
+				t.Fatal("expecting the resolver to be closed", index, err)
+			}
+		}
+	})
+}
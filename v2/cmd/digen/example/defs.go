@@ -0,0 +1,176 @@
+// Package example contains definitions with a resolver generated by digen,
+// and is used to test that the generated resolver behaves the same as the
+// resolver returned by di.NewResolver
+package example
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/clavoie/di/v2"
+)
+
+//go:generate go run github.com/clavoie/di/v2/cmd/digen
+
+// Defs are the definitions of the package
+var Defs = []*di.Def{
+	{Constructor: NewConfig, Lifetime: di.Singleton},
+	{Constructor: NewPool, Lifetime: di.Singleton},
+	{Constructor: NewStore, Lifetime: di.PerResolve},
+	{Constructor: NewRepo, Lifetime: di.PerDependency},
+	{Constructor: NewSession, Lifetime: di.PerHttpRequest},
+	{Constructor: NewLocator, Lifetime: di.PerDependency},
+	{Constructor: NewFailing, Lifetime: di.PerDependency},
+}
+
+// LoggerDefs are the definitions of the logger of the package
+var LoggerDefs = []*di.Def{
+	{Constructor: NewLogger, Lifetime: di.Singleton},
+}
+
+// HttpDefs are the http handlers of the package
+var HttpDefs = []*di.HttpDef{
+	{Handler: Index, Pattern: "/"},
+}
+
+// counter is incremented for every value created
+var counter = 0
+
+// next returns the next value of counter
+func next() int {
+	counter += 1
+	return counter
+}
+
+type Config interface{ ID() int }
+type Store interface{ ID() int }
+type Failing interface{}
+type Locator interface{ Resolver() di.IResolver }
+
+type Repo interface {
+	Config() (Config, error)
+	Store() Store
+}
+
+type Session interface {
+	ID() int
+	IsClosed() bool
+	Request() *http.Request
+}
+
+type Pool interface{ Close() error }
+
+// Disposed are the names of the Singletons disposed of, in order
+var Disposed = []string{}
+
+// Hooks are the lifecycle hooks called, in order
+var Hooks = []string{}
+
+// ConfigStartErr is returned by the Di_Start of Config if it is not nil
+var ConfigStartErr error
+
+type config struct{ id int }
+
+func (c *config) Close() error { Disposed = append(Disposed, "config"); return nil }
+func (c *config) ID() int      { return c.id }
+
+func (c *config) Di_Start(ctx context.Context) error {
+	Hooks = append(Hooks, "config.start")
+	return ConfigStartErr
+}
+
+func (c *config) Di_Stop(ctx context.Context) error {
+	Hooks = append(Hooks, "config.stop")
+	return nil
+}
+
+func NewConfig(pool Pool) (Config, error) { return &config{next()}, nil }
+
+// ErrPool is returned when a Pool is closed
+var ErrPool = errors.New("pool")
+
+type pool struct{}
+
+func (p *pool) Close() error { Disposed = append(Disposed, "pool"); return ErrPool }
+
+func (p *pool) Di_Start(ctx context.Context) error {
+	Hooks = append(Hooks, "pool.start")
+	return nil
+}
+
+func (p *pool) Di_Stop(ctx context.Context) error {
+	Hooks = append(Hooks, "pool.stop")
+	return nil
+}
+
+func NewPool() Pool { return new(pool) }
+
+type store struct{ id int }
+
+func (s *store) ID() int { return s.id }
+
+func NewStore(config Config) Store { return &store{next()} }
+
+type repo struct {
+	config func() (Config, error)
+	store  Store
+}
+
+func (r *repo) Config() (Config, error) { return r.config() }
+func (r *repo) Store() Store            { return r.store }
+
+func NewRepo(store Store, config func() (Config, error)) Repo { return &repo{config, store} }
+
+type session struct {
+	id       int
+	isClosed bool
+	r        *http.Request
+}
+
+func (s *session) Di_HttpClose()          { s.isClosed = true }
+func (s *session) ID() int                { return s.id }
+func (s *session) IsClosed() bool         { return s.isClosed }
+func (s *session) Request() *http.Request { return s.r }
+
+func NewSession(w http.ResponseWriter, r *http.Request) Session { return &session{next(), false, r} }
+
+type locator struct{ resolver di.IResolver }
+
+func (l *locator) Resolver() di.IResolver { return l.resolver }
+
+func NewLocator(resolver di.IResolver) Locator { return &locator{resolver} }
+
+// ErrFailing is returned by NewFailing
+var ErrFailing = errors.New("failing")
+
+func NewFailing(store Store) (Failing, error) { return nil, ErrFailing }
+
+// Logger records the durations it is called with
+type Logger struct{ Durations []time.Duration }
+
+func (l *Logger) HttpDuration(duration time.Duration) { l.Durations = append(l.Durations, duration) }
+
+func (l *Logger) Di_Dispose(ctx context.Context) error {
+	Disposed = append(Disposed, "logger")
+	return nil
+}
+
+func (l *Logger) Di_Stop(ctx context.Context) error {
+	Hooks = append(Hooks, "logger.stop")
+	return nil
+}
+
+func NewLogger() di.ILogger { return new(Logger) }
+
+// Handled are the sessions of each request handled by Index
+var Handled = []Session{}
+
+func Index(w http.ResponseWriter, repo Repo, session Session, again Session) {
+	if session != again {
+		panic("PerHttpRequest values are not the same")
+	}
+
+	Handled = append(Handled, session)
+}
@@ -0,0 +1,906 @@
+// Code generated by digen. DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	di "github.com/clavoie/di/v2"
+)
+
+// digenErrorType is typeof(error)
+var digenErrorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// digenRequestType and digenResponseWriterType are the types of the http request
+var digenRequestType = reflect.TypeOf((**http.Request)(nil)).Elem()
+var digenResponseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+
+// digenTypes are the types of the definitions
+var (
+	digenType0 = reflect.TypeOf((*Config)(nil)).Elem()
+	digenType1 = reflect.TypeOf((*Pool)(nil)).Elem()
+	digenType2 = reflect.TypeOf((*Store)(nil)).Elem()
+	digenType3 = reflect.TypeOf((*Repo)(nil)).Elem()
+	digenType4 = reflect.TypeOf((*Session)(nil)).Elem()
+	digenType5 = reflect.TypeOf((*Locator)(nil)).Elem()
+	digenType6 = reflect.TypeOf((*Failing)(nil)).Elem()
+	digenType7 = reflect.TypeOf((*di.ILogger)(nil)).Elem()
+)
+
+var _ di.IHttpResolver = (*digenResolver)(nil)
+var _ di.IResolver = (*digenScope)(nil)
+
+// NewGeneratedResolver returns the same resolver as di.NewResolver(errFn, Defs, LoggerDefs), except
+// that the definitions are resolved by generated code instead of by reflection
+func NewGeneratedResolver(errFn func(*di.ErrResolve, http.ResponseWriter, *http.Request)) (di.IHttpResolver, error) {
+	meta, err := di.NewResolver(errFn, Defs, LoggerDefs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &digenResolver{errFn: errFn, meta: meta}, nil
+}
+
+// digenResolver is the generated resolver. Definitions and the graph are
+// described by meta, a resolver of the same definitions which is never
+// used to resolve a value
+type digenResolver struct {
+	closed int32
+	errFn  func(*di.ErrResolve, http.ResponseWriter, *http.Request)
+	meta   di.IHttpResolver
+
+	lifecycleLock sync.Mutex
+	started       []digenSingleton
+
+	singletonLock0 sync.Mutex
+	singleton0     Config
+	hasSingleton0  bool
+
+	singletonLock1 sync.Mutex
+	singleton1     Pool
+	hasSingleton1  bool
+
+	singletonLock7 sync.Mutex
+	singleton7     di.ILogger
+	hasSingleton7  bool
+}
+
+// digenScope resolves the values of one call of the resolver, or of one
+// http request, and is injected as the di.IResolver
+type digenScope struct {
+	closables []di.IHttpClosable
+	isHttp    bool
+	r         *digenResolver
+	req       *http.Request
+	w         http.ResponseWriter
+
+	value2    Store
+	hasValue2 bool
+
+	value4    Session
+	hasValue4 bool
+}
+
+func (r *digenResolver) Close(ctx context.Context) error {
+	if atomic.SwapInt32(&r.closed, 1) == 1 {
+		return nil
+	}
+
+	r.lifecycleLock.Lock()
+	errs := digenStop(ctx, r.started)
+	r.started = nil
+	r.lifecycleLock.Unlock()
+
+	// Singletons are disposed of in reverse dependency order
+	disposables := make([]digenSingleton, 0)
+	r.singletonLock0.Lock()
+	if r.hasSingleton0 {
+		disposables = append(disposables, digenSingleton{r.singleton0, digenType0})
+	}
+	r.singletonLock0.Unlock()
+
+	r.singletonLock1.Lock()
+	if r.hasSingleton1 {
+		disposables = append(disposables, digenSingleton{r.singleton1, digenType1})
+	}
+	r.singletonLock1.Unlock()
+
+	r.singletonLock7.Lock()
+	if r.hasSingleton7 {
+		disposables = append(disposables, digenSingleton{r.singleton7, digenType7})
+	}
+	r.singletonLock7.Unlock()
+
+	return digenDispose(ctx, errs, disposables)
+}
+
+func (r *digenResolver) Curry(fn interface{}) (interface{}, *di.ErrResolve) {
+	return (&digenScope{r: r}).Curry(fn)
+}
+
+func (r *digenResolver) Definitions() []*di.DefInfo {
+	return r.meta.Definitions()
+}
+
+func (r *digenResolver) HttpHandler(fn interface{}) (func(http.ResponseWriter, *http.Request), error) {
+	_, err := r.meta.HttpHandler(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typedFn := fn.(type) {
+	case func(http.ResponseWriter, Repo, Session, Session):
+		return r.handler(reflect.TypeOf(fn), func(s *digenScope) (func(), *di.ErrResolve) {
+			p0, err := s.responseWriter(nil)
+			if err != nil {
+				return nil, err
+			}
+
+			p1, err := s.resolve3(nil)
+			if err != nil {
+				return nil, err
+			}
+
+			p2, err := s.resolve4(nil)
+			if err != nil {
+				return nil, err
+			}
+
+			p3, err := s.resolve4(nil)
+			if err != nil {
+				return nil, err
+			}
+
+			return func() { typedFn(p0, p1, p2, p3) }, nil
+		}), nil
+	}
+
+	fnValue := reflect.ValueOf(fn)
+	return r.handler(fnValue.Type(), func(s *digenScope) (func(), *di.ErrResolve) {
+		args, err := s.resolveArgs(fnValue.Type())
+		if err != nil {
+			return nil, err
+		}
+
+		if fnValue.Type().IsVariadic() {
+			return func() { fnValue.CallSlice(args) }, nil
+		}
+
+		return func() { fnValue.Call(args) }, nil
+	}), nil
+}
+
+func (r *digenResolver) Invoke(fn interface{}) *di.ErrResolve {
+	return (&digenScope{r: r}).Invoke(fn)
+}
+
+func (r *digenResolver) Resolve(ptrToIface interface{}) *di.ErrResolve {
+	return (&digenScope{r: r}).Resolve(ptrToIface)
+}
+
+func (r *digenResolver) ResolveNamed(name string, ptrToIface interface{}) *di.ErrResolve {
+	return (&digenScope{r: r}).ResolveNamed(name, ptrToIface)
+}
+
+func (r *digenResolver) SetDefaultServeMux(httpDefs []*di.HttpDef) error {
+	for _, httpDef := range httpDefs {
+		injectedHandler, err := r.HttpHandler(httpDef.Handler)
+		if err != nil {
+			return err
+		}
+
+		http.HandleFunc(httpDef.Pattern, injectedHandler)
+	}
+
+	return nil
+}
+
+func (r *digenResolver) Start(ctx context.Context) error {
+	r.lifecycleLock.Lock()
+	defer r.lifecycleLock.Unlock()
+
+	if r.isClosed() {
+		return di.ErrClosed
+	}
+
+	if r.started != nil {
+		return di.ErrStarted
+	}
+
+	// Singletons are started in dependency order
+	s := &digenScope{r: r}
+	resolves := []func() (interface{}, *di.ErrResolve){
+		func() (interface{}, *di.ErrResolve) {
+			value, err := s.resolve7(nil)
+			return value, err
+		},
+		func() (interface{}, *di.ErrResolve) {
+			value, err := s.resolve1(nil)
+			return value, err
+		},
+		func() (interface{}, *di.ErrResolve) {
+			value, err := s.resolve0(nil)
+			return value, err
+		},
+	}
+	types := []reflect.Type{digenType7, digenType1, digenType0}
+
+	seen := make(map[interface{}]bool)
+	started := make([]digenSingleton, 0)
+	for index, resolve := range resolves {
+		value, err := resolve()
+		if err != nil {
+			return digenRollback(ctx, started, err)
+		}
+
+		switch value.(type) {
+		case di.IStartable, di.IStoppable:
+		default:
+			continue
+		}
+
+		if reflect.TypeOf(value).Comparable() {
+			if seen[value] {
+				continue
+			}
+
+			seen[value] = true
+		}
+
+		if startable, isStartable := value.(di.IStartable); isStartable {
+			if err := digenRunHook(ctx, startable.Di_Start); err != nil {
+				return digenRollback(ctx, started, fmt.Errorf("di: starting %v: %w", types[index], err))
+			}
+		}
+
+		started = append(started, digenSingleton{value, types[index]})
+	}
+
+	r.started = started
+	return nil
+}
+
+func (r *digenResolver) Stop(ctx context.Context) error {
+	r.lifecycleLock.Lock()
+	defer r.lifecycleLock.Unlock()
+
+	errs := digenStop(ctx, r.started)
+	r.started = nil
+	if len(errs) > 0 {
+		return &di.ErrLifecycle{Errs: errs}
+	}
+
+	return nil
+}
+
+func (r *digenResolver) WriteGraph(w io.Writer, format di.GraphFormat) error {
+	return r.meta.WriteGraph(w, format)
+}
+
+// isClosed returns true if Close has been called on the resolver
+func (r *digenResolver) isClosed() bool {
+	return atomic.LoadInt32(&r.closed) == 1
+}
+
+// handler returns an http handler which resolves the dependencies of a
+// handler func of fnType with resolve, and then calls the func
+func (r *digenResolver) handler(fnType reflect.Type, resolve func(*digenScope) (func(), *di.ErrResolve)) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		epoch := time.Now()
+		if r.isClosed() {
+			r.errFn(digenErr(nil, di.ErrClosed, fnType), w, req)
+			return
+		}
+
+		s := &digenScope{isHttp: true, r: r, req: req, w: w}
+		call, err := resolve(s)
+		if err != nil {
+			r.errFn(err, w, req)
+			return
+		}
+
+		for _, closable := range s.closables {
+			defer closable.Di_HttpClose()
+		}
+
+		duration := time.Since(epoch)
+		logger, err := s.resolve7(nil)
+		if err != nil {
+			r.errFn(err, w, req)
+			return
+		}
+
+		logger.HttpDuration(duration)
+
+		call()
+	}
+}
+
+// digenSingleton is a Singleton to start, stop, or dispose of
+type digenSingleton struct {
+	value interface{}
+	rtype reflect.Type
+}
+
+// digenRunHook calls hook, returning the error of ctx instead if ctx is
+// done first
+func digenRunHook(ctx context.Context, hook func(context.Context) error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// digenStop calls Di_Stop on each of started which is a di.IStoppable, in
+// reverse order, returning every error
+func digenStop(ctx context.Context, started []digenSingleton) []error {
+	errs := make([]error, 0)
+	for index := len(started) - 1; index >= 0; index -= 1 {
+		stoppable, isStoppable := started[index].value.(di.IStoppable)
+		if isStoppable == false {
+			continue
+		}
+
+		if err := digenRunHook(ctx, stoppable.Di_Stop); err != nil {
+			errs = append(errs, fmt.Errorf("di: stopping %v: %w", started[index].rtype, err))
+		}
+	}
+
+	return errs
+}
+
+// digenRollback stops started after err stopped the resolver from starting
+func digenRollback(ctx context.Context, started []digenSingleton, err error) error {
+	return &di.ErrLifecycle{Errs: append([]error{err}, digenStop(ctx, started)...)}
+}
+
+// digenDispose disposes of each value which is a di.IDisposable or an
+// io.Closer, in order, returning errs and every error in a *di.ErrClose
+func digenDispose(ctx context.Context, errs []error, disposables []digenSingleton) error {
+	disposed := make(map[interface{}]bool)
+	for _, disposable := range disposables {
+		switch disposable.value.(type) {
+		case di.IDisposable, io.Closer:
+		default:
+			continue
+		}
+
+		if reflect.TypeOf(disposable.value).Comparable() {
+			if disposed[disposable.value] {
+				continue
+			}
+
+			disposed[disposable.value] = true
+		}
+
+		if ctx.Err() != nil {
+			errs = append(errs, ctx.Err())
+			break
+		}
+
+		var err error
+		switch value := disposable.value.(type) {
+		case di.IDisposable:
+			err = value.Di_Dispose(ctx)
+		case io.Closer:
+			err = value.Close()
+		}
+
+		if err != nil {
+			errs = append(errs, fmt.Errorf("di: closing %v: %w", disposable.rtype, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &di.ErrClose{Errs: errs}
+	}
+
+	return nil
+}
+
+func (s *digenScope) Curry(fn interface{}) (interface{}, *di.ErrResolve) {
+	fnValue := reflect.ValueOf(fn)
+	if fnValue.Kind() != reflect.Func {
+		return nil, digenErr(nil, fmt.Errorf("di: the generated resolver only injects plain funcs, annotated funcs are not supported: %T", fn), reflect.TypeOf(fn))
+	}
+
+	fnType := fnValue.Type()
+	numIn := fnType.NumIn()
+	isVariadic := fnType.IsVariadic()
+	knowns := make([]reflect.Value, numIn)
+	callTypes := make([]reflect.Type, 0, numIn)
+	for index := 0; index < numIn; index += 1 {
+		inType := fnType.In(index)
+		if index == numIn-1 && isVariadic {
+			callTypes = append(callTypes, inType)
+			continue
+		}
+
+		value, err := s.resolveArg(nil, inType)
+		if err != nil {
+			if _, isDefMissing := err.Err.(*di.ErrDefMissing); isDefMissing {
+				callTypes = append(callTypes, inType)
+				continue
+			}
+
+			return nil, err
+		}
+
+		knowns[index] = value
+	}
+
+	outTypes := make([]reflect.Type, fnType.NumOut())
+	for index := range outTypes {
+		outTypes[index] = fnType.Out(index)
+	}
+
+	curryFnType := reflect.FuncOf(callTypes, outTypes, isVariadic)
+	return reflect.MakeFunc(curryFnType, func(ins []reflect.Value) []reflect.Value {
+		callVals := make([]reflect.Value, numIn)
+		callIndex := 0
+		for index := range callVals {
+			if knowns[index].IsValid() {
+				callVals[index] = knowns[index]
+			} else {
+				callVals[index] = ins[callIndex]
+				callIndex += 1
+			}
+		}
+
+		if isVariadic {
+			return fnValue.CallSlice(callVals)
+		}
+
+		return fnValue.Call(callVals)
+	}).Interface(), nil
+}
+
+func (s *digenScope) Invoke(fn interface{}) *di.ErrResolve {
+	newFn, err := s.Curry(fn)
+	if err != nil {
+		return err
+	}
+
+	fnValue := reflect.ValueOf(newFn)
+	fnType := fnValue.Type()
+	if fnType.NumIn() > 0 {
+		return digenErr(nil, fmt.Errorf("di: Invoke: cannot invoke a func with input parameters: %v", fnType.NumIn()), fnType)
+	}
+
+	outValues := fnValue.Call(nil)
+	if fnType.NumOut() == 1 && fnType.Out(0) == digenErrorType {
+		if errVal := outValues[0].Interface(); errVal != nil {
+			return digenErr(nil, errVal.(error), fnType)
+		}
+	}
+
+	return nil
+}
+
+func (s *digenScope) Resolve(ptrToIface interface{}) *di.ErrResolve {
+	return s.ResolveNamed("", ptrToIface)
+}
+
+func (s *digenScope) ResolveNamed(name string, ptrToIface interface{}) *di.ErrResolve {
+	if ptrValue := reflect.ValueOf(ptrToIface); s.r.isClosed() && ptrValue.Kind() == reflect.Ptr {
+		return digenErr(nil, di.ErrClosed, ptrValue.Type().Elem())
+	}
+
+	if name == "" {
+		switch ptr := ptrToIface.(type) {
+		case *di.IResolver:
+			*ptr = s
+			return nil
+		case *Config:
+			value, err := s.resolve0(nil)
+			if err != nil {
+				return err
+			}
+
+			*ptr = value
+			return nil
+		case *Pool:
+			value, err := s.resolve1(nil)
+			if err != nil {
+				return err
+			}
+
+			*ptr = value
+			return nil
+		case *Store:
+			value, err := s.resolve2(nil)
+			if err != nil {
+				return err
+			}
+
+			*ptr = value
+			return nil
+		case *Repo:
+			value, err := s.resolve3(nil)
+			if err != nil {
+				return err
+			}
+
+			*ptr = value
+			return nil
+		case *Session:
+			value, err := s.resolve4(nil)
+			if err != nil {
+				return err
+			}
+
+			*ptr = value
+			return nil
+		case *Locator:
+			value, err := s.resolve5(nil)
+			if err != nil {
+				return err
+			}
+
+			*ptr = value
+			return nil
+		case *Failing:
+			value, err := s.resolve6(nil)
+			if err != nil {
+				return err
+			}
+
+			*ptr = value
+			return nil
+		case *di.ILogger:
+			value, err := s.resolve7(nil)
+			if err != nil {
+				return err
+			}
+
+			*ptr = value
+			return nil
+		}
+	}
+
+	ptrValue := reflect.ValueOf(ptrToIface)
+	if ptrValue.Kind() != reflect.Ptr {
+		return digenErr(nil, fmt.Errorf("di: ptrToIFace must be a pointer type: %v", ptrValue.Type()), ptrValue.Type())
+	}
+
+	if name != "" {
+		return digenErr(nil, &di.ErrDefMissing{Name: name, Type: ptrValue.Type().Elem()}, ptrValue.Type().Elem())
+	}
+
+	value, err := s.resolveArg(nil, ptrValue.Type().Elem())
+	if err != nil {
+		return err
+	}
+
+	ptrValue.Elem().Set(value)
+	return nil
+}
+
+// resolveArg resolves a value of rtype, which is either a definition or
+// supplied by the resolver
+func (s *digenScope) resolveArg(depChain []reflect.Type, rtype reflect.Type) (reflect.Value, *di.ErrResolve) {
+	var value interface{}
+	var err *di.ErrResolve
+
+	switch rtype {
+	case digenRequestType:
+		value, err = s.request(depChain)
+	case digenResponseWriterType:
+		value, err = s.responseWriter(depChain)
+	case digenType0:
+		value, err = s.resolve0(depChain)
+	case digenType1:
+		value, err = s.resolve1(depChain)
+	case digenType2:
+		value, err = s.resolve2(depChain)
+	case digenType3:
+		value, err = s.resolve3(depChain)
+	case digenType4:
+		value, err = s.resolve4(depChain)
+	case digenType5:
+		value, err = s.resolve5(depChain)
+	case digenType6:
+		value, err = s.resolve6(depChain)
+	case digenType7:
+		value, err = s.resolve7(depChain)
+	default:
+		if rtype == reflect.TypeOf((*di.IResolver)(nil)).Elem() {
+			return reflect.ValueOf(s), nil
+		}
+
+		if unsupportedErr := digenUnsupported(rtype); unsupportedErr != nil {
+			return reflect.Value{}, digenErr(depChain, unsupportedErr, rtype)
+		}
+
+		return reflect.Value{}, digenErr(depChain, &di.ErrDefMissing{Type: rtype}, rtype)
+	}
+
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	if value == nil {
+		return reflect.Zero(rtype), nil
+	}
+
+	return reflect.ValueOf(value), nil
+}
+
+// resolveArgs resolves a value for each parameter of fnType
+func (s *digenScope) resolveArgs(fnType reflect.Type) ([]reflect.Value, *di.ErrResolve) {
+	args := make([]reflect.Value, fnType.NumIn())
+	for index := range args {
+		arg, err := s.resolveArg(nil, fnType.In(index))
+		if err != nil {
+			return nil, err
+		}
+
+		args[index] = arg
+	}
+
+	return args, nil
+}
+
+// request returns the request the scope was created for
+func (s *digenScope) request(depChain []reflect.Type) (*http.Request, *di.ErrResolve) {
+	if s.isHttp == false {
+		return nil, digenErr(depChain, &di.ErrDefMissing{Type: digenRequestType}, digenRequestType)
+	}
+
+	return s.req, nil
+}
+
+// responseWriter returns the response writer of the request the scope was
+// created for
+func (s *digenScope) responseWriter(depChain []reflect.Type) (http.ResponseWriter, *di.ErrResolve) {
+	if s.isHttp == false {
+		return nil, digenErr(depChain, &di.ErrDefMissing{Type: digenResponseWriterType}, digenResponseWriterType)
+	}
+
+	return s.w, nil
+}
+
+// closable records value to be closed when the http request of the scope
+// completes, if it is a di.IHttpClosable
+func (s *digenScope) closable(value interface{}) {
+	if closable, isClosable := value.(di.IHttpClosable); isClosable {
+		s.closables = append(s.closables, closable)
+	}
+}
+
+// digenDefined returns true if rtype has a definition, or is supplied by
+// the resolver itself
+func digenDefined(rtype reflect.Type) bool {
+	switch rtype {
+	case digenRequestType, digenResponseWriterType, digenType0, digenType1, digenType2, digenType3, digenType4, digenType5, digenType6, digenType7:
+		return true
+	}
+
+	return rtype == reflect.TypeOf((*di.IResolver)(nil)).Elem()
+}
+
+// digenUnsupported returns an error if rtype is a provider of a defined
+// type or a parameter object, which the generated resolver does not inject
+func digenUnsupported(rtype reflect.Type) error {
+	if rtype.Kind() == reflect.Func && rtype.NumIn() == 0 && rtype.NumOut() == 2 && rtype.Out(1) == digenErrorType && rtype.IsVariadic() == false && digenDefined(rtype.Out(0)) {
+		return fmt.Errorf("di: the generated resolver cannot inject a provider into a func it did not generate: %v", rtype)
+	}
+
+	if rtype.Kind() != reflect.Struct {
+		return nil
+	}
+
+	for index := 0; index < rtype.NumField(); index += 1 {
+		if field := rtype.Field(index); field.Anonymous && field.Type == reflect.TypeOf(di.In{}) {
+			return fmt.Errorf("di: the generated resolver cannot inject a parameter object into a func it did not generate: %v", rtype)
+		}
+	}
+
+	return nil
+}
+
+// digenErr returns a new *di.ErrResolve
+func digenErr(depChain []reflect.Type, err error, rtype reflect.Type) *di.ErrResolve {
+	if depChain == nil {
+		depChain = make([]reflect.Type, 0, 1)
+	}
+
+	return &di.ErrResolve{DependencyChain: depChain, Err: err, Type: rtype}
+}
+
+// resolve0 resolves example.Config, a Singleton constructed by NewConfig
+func (s *digenScope) resolve0(depChain []reflect.Type) (value Config, err *di.ErrResolve) {
+	s.r.singletonLock0.Lock()
+	defer s.r.singletonLock0.Unlock()
+	if s.r.isClosed() {
+		return value, digenErr(depChain, di.ErrClosed, digenType0)
+	}
+
+	if s.r.hasSingleton0 {
+		return s.r.singleton0, nil
+	}
+
+	childDepChain := append(depChain[:len(depChain):len(depChain)], digenType0)
+	p0, err := s.resolve1(childDepChain)
+	if err != nil {
+		return value, err
+	}
+
+	value, ctorErr := NewConfig(p0)
+	if ctorErr != nil {
+		return value, digenErr(depChain, ctorErr, digenType0)
+	}
+
+	s.closable(value)
+	s.r.singleton0, s.r.hasSingleton0 = value, true
+	return value, nil
+}
+
+// resolve1 resolves example.Pool, a Singleton constructed by NewPool
+func (s *digenScope) resolve1(depChain []reflect.Type) (value Pool, err *di.ErrResolve) {
+	s.r.singletonLock1.Lock()
+	defer s.r.singletonLock1.Unlock()
+	if s.r.isClosed() {
+		return value, digenErr(depChain, di.ErrClosed, digenType1)
+	}
+
+	if s.r.hasSingleton1 {
+		return s.r.singleton1, nil
+	}
+
+	value = NewPool()
+
+	s.closable(value)
+	s.r.singleton1, s.r.hasSingleton1 = value, true
+	return value, nil
+}
+
+// resolve2 resolves example.Store, a PerResolve constructed by NewStore
+func (s *digenScope) resolve2(depChain []reflect.Type) (value Store, err *di.ErrResolve) {
+	if s.r.isClosed() {
+		return value, digenErr(depChain, di.ErrClosed, digenType2)
+	}
+
+	if s.hasValue2 {
+		return s.value2, nil
+	}
+
+	childDepChain := append(depChain[:len(depChain):len(depChain)], digenType2)
+	p0, err := s.resolve0(childDepChain)
+	if err != nil {
+		return value, err
+	}
+
+	value = NewStore(p0)
+
+	s.closable(value)
+	s.value2, s.hasValue2 = value, true
+	return value, nil
+}
+
+// resolve3 resolves example.Repo, a PerDependency constructed by NewRepo
+func (s *digenScope) resolve3(depChain []reflect.Type) (value Repo, err *di.ErrResolve) {
+	if s.r.isClosed() {
+		return value, digenErr(depChain, di.ErrClosed, digenType3)
+	}
+
+	childDepChain := append(depChain[:len(depChain):len(depChain)], digenType3)
+	p0, err := s.resolve2(childDepChain)
+	if err != nil {
+		return value, err
+	}
+
+	p1 := func() (Config, error) {
+		value, err := s.resolve0(childDepChain)
+		if err != nil {
+			return value, err
+		}
+
+		return value, nil
+	}
+
+	value = NewRepo(p0, p1)
+
+	s.closable(value)
+	return value, nil
+}
+
+// resolve4 resolves example.Session, a PerHttpRequest constructed by NewSession
+func (s *digenScope) resolve4(depChain []reflect.Type) (value Session, err *di.ErrResolve) {
+	if s.r.isClosed() {
+		return value, digenErr(depChain, di.ErrClosed, digenType4)
+	}
+
+	if s.hasValue4 {
+		return s.value4, nil
+	}
+
+	childDepChain := append(depChain[:len(depChain):len(depChain)], digenType4)
+	p0, err := s.responseWriter(childDepChain)
+	if err != nil {
+		return value, err
+	}
+
+	p1, err := s.request(childDepChain)
+	if err != nil {
+		return value, err
+	}
+
+	value = NewSession(p0, p1)
+
+	s.closable(value)
+	s.value4, s.hasValue4 = value, true
+	return value, nil
+}
+
+// resolve5 resolves example.Locator, a PerDependency constructed by NewLocator
+func (s *digenScope) resolve5(depChain []reflect.Type) (value Locator, err *di.ErrResolve) {
+	if s.r.isClosed() {
+		return value, digenErr(depChain, di.ErrClosed, digenType5)
+	}
+
+	p0 := di.IResolver(s)
+
+	value = NewLocator(p0)
+
+	s.closable(value)
+	return value, nil
+}
+
+// resolve6 resolves example.Failing, a PerDependency constructed by NewFailing
+func (s *digenScope) resolve6(depChain []reflect.Type) (value Failing, err *di.ErrResolve) {
+	if s.r.isClosed() {
+		return value, digenErr(depChain, di.ErrClosed, digenType6)
+	}
+
+	childDepChain := append(depChain[:len(depChain):len(depChain)], digenType6)
+	p0, err := s.resolve2(childDepChain)
+	if err != nil {
+		return value, err
+	}
+
+	value, ctorErr := NewFailing(p0)
+	if ctorErr != nil {
+		return value, digenErr(depChain, ctorErr, digenType6)
+	}
+
+	s.closable(value)
+	return value, nil
+}
+
+// resolve7 resolves di.ILogger, a Singleton constructed by NewLogger
+func (s *digenScope) resolve7(depChain []reflect.Type) (value di.ILogger, err *di.ErrResolve) {
+	s.r.singletonLock7.Lock()
+	defer s.r.singletonLock7.Unlock()
+	if s.r.isClosed() {
+		return value, digenErr(depChain, di.ErrClosed, digenType7)
+	}
+
+	if s.r.hasSingleton7 {
+		return s.r.singleton7, nil
+	}
+
+	value = NewLogger()
+
+	s.closable(value)
+	s.r.singleton7, s.r.hasSingleton7 = value, true
+	return value, nil
+}
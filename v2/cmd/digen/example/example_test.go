@@ -0,0 +1,405 @@
+package example
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/clavoie/di/v2"
+)
+
+func errFn(err *di.ErrResolve, w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusInternalServerError)
+}
+
+// forEachResolver runs fn with both the reflective and the generated
+// resolver
+func forEachResolver(t *testing.T, fn func(*testing.T, di.IHttpResolver)) {
+	t.Run("Reflective", func(t *testing.T) {
+		resolver, err := di.NewResolver(errFn, Defs, LoggerDefs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fn(t, resolver)
+	})
+	t.Run("Generated", func(t *testing.T) {
+		resolver, err := NewGeneratedResolver(errFn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fn(t, resolver)
+	})
+}
+
+func TestGenerated(t *testing.T) {
+	t.Run("Singleton", func(t *testing.T) {
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			var config1, config2 Config
+			if err := resolver.Resolve(&config1); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := resolver.Resolve(&config2); err != nil {
+				t.Fatal(err)
+			}
+
+			if config1 != config2 {
+				t.Fatal("expecting the same Singleton")
+			}
+		})
+	})
+	t.Run("PerResolve", func(t *testing.T) {
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			var repo1, repo2 Repo
+			if err := resolver.Resolve(&repo1); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := resolver.Resolve(&repo2); err != nil {
+				t.Fatal(err)
+			}
+
+			if repo1 == repo2 {
+				t.Fatal("expecting a new PerDependency value")
+			}
+
+			if repo1.Store() == repo2.Store() {
+				t.Fatal("expecting a new PerResolve value per Resolve")
+			}
+
+			err := resolver.Invoke(func(repo1, repo2 Repo) {
+				if repo1.Store() != repo2.Store() {
+					t.Fatal("expecting the same PerResolve value within a resolve")
+				}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+	})
+	t.Run("Provider", func(t *testing.T) {
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			var repo Repo
+			var config Config
+			if err := resolver.Resolve(&repo); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := resolver.Resolve(&config); err != nil {
+				t.Fatal(err)
+			}
+
+			providedConfig, err := repo.Config()
+			if err != nil || providedConfig != config {
+				t.Fatal("unexpected provided value", providedConfig, err)
+			}
+		})
+	})
+	t.Run("IResolver", func(t *testing.T) {
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			var locator Locator
+			if err := resolver.Resolve(&locator); err != nil {
+				t.Fatal(err)
+			}
+
+			var store1, store2 Store
+			if err := locator.Resolver().Resolve(&store1); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := locator.Resolver().Resolve(&store2); err != nil {
+				t.Fatal(err)
+			}
+
+			if store1 != store2 {
+				t.Fatal("expecting the IResolver to share the values of its resolve")
+			}
+		})
+	})
+	t.Run("Errors", func(t *testing.T) {
+		var expected []string
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			var failing Failing
+			var session Session
+			var named Store
+			var missing http.Handler
+
+			errs := []*di.ErrResolve{
+				resolver.Resolve(&failing),
+				resolver.Resolve(&session),
+				resolver.ResolveNamed("name", &named),
+				resolver.Resolve(&missing),
+				resolver.Invoke(func(Failing) {}),
+				resolver.Invoke(func(http.Handler) {}),
+			}
+
+			msgs := make([]string, len(errs))
+			for index, err := range errs {
+				if err == nil {
+					t.Fatal("expecting error", index)
+				}
+
+				msgs[index] = err.String()
+			}
+
+			if errs[0].Err != ErrFailing {
+				t.Fatal("expecting constructor error", errs[0].Err)
+			}
+
+			if expected == nil {
+				expected = msgs
+				return
+			}
+
+			for index, msg := range msgs {
+				if msg != expected[index] {
+					t.Fatal("expecting the same error", msg, expected[index])
+				}
+			}
+		})
+	})
+	t.Run("Curry", func(t *testing.T) {
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			curried, err := resolver.Curry(func(name string, store Store, suffix ...string) string {
+				return name + suffix[0]
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value := curried.(func(string, ...string) string)("a", "b"); value != "ab" {
+				t.Fatal("unexpected value", value)
+			}
+
+			curried, err = resolver.Curry(func(store Store, load func() (string, error)) string {
+				value, _ := load()
+				return value
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value := curried.(func(func() (string, error)) string)(func() (string, error) { return "c", nil }); value != "c" {
+				t.Fatal("unexpected value", value)
+			}
+		})
+	})
+	t.Run("Unsupported", func(t *testing.T) {
+		resolver, err := NewGeneratedResolver(errFn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fns := []interface{}{
+			func(store func() (Store, error)) {},
+			func(p struct {
+				di.In
+				Store Store
+			}) {
+			},
+			di.Params(func(store Store) {}, "optional"),
+		}
+
+		for index, fn := range fns {
+			resolveErr := resolver.Invoke(fn)
+			if resolveErr == nil || strings.Contains(resolveErr.Err.Error(), "generated resolver") == false {
+				t.Fatal("expecting an unsupported err", index, resolveErr)
+			}
+		}
+
+		handler, err := resolver.HttpHandler(func(store func() (Store, error)) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusInternalServerError {
+			t.Fatal("expecting the unsupported err to be passed to errFn", w.Code)
+		}
+	})
+	t.Run("HttpHandler", func(t *testing.T) {
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			var logger di.ILogger
+			if err := resolver.Resolve(&logger); err != nil {
+				t.Fatal(err)
+			}
+
+			handler, err := resolver.HttpHandler(Index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			r := httptest.NewRequest("GET", "/", nil)
+			handler(httptest.NewRecorder(), r)
+			handler(httptest.NewRecorder(), r)
+
+			handled := Handled[len(Handled)-2:]
+			if handled[0] == handled[1] {
+				t.Fatal("expecting a PerHttpRequest value per request")
+			}
+
+			if handled[0].IsClosed() == false || handled[0].Request() != r {
+				t.Fatal("expecting a closed session of the request")
+			}
+
+			if len(logger.(*Logger).Durations) != 2 {
+				t.Fatal("expecting a duration per request")
+			}
+
+			var called Session
+			reflected, err := resolver.HttpHandler(func(session Session, store Store) {
+				called = session
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			reflected(httptest.NewRecorder(), r)
+			if called == nil || called.IsClosed() == false {
+				t.Fatal("expecting a handler of any signature", called)
+			}
+
+			w := httptest.NewRecorder()
+			failing, err := resolver.HttpHandler(func(Failing) {})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			failing(w, r)
+			if w.Code != http.StatusInternalServerError {
+				t.Fatal("expecting errFn to be called", w.Code)
+			}
+		})
+	})
+	t.Run("Close", func(t *testing.T) {
+		var expected string
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			var config Config
+			var logger di.ILogger
+			if err := resolver.Resolve(&config); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := resolver.Resolve(&logger); err != nil {
+				t.Fatal(err)
+			}
+
+			handler, err := resolver.HttpHandler(Index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			Disposed = nil
+			err = resolver.Close(context.Background())
+
+			var closeErr *di.ErrClose
+			if errors.As(err, &closeErr) == false || len(closeErr.Errs) != 1 || errors.Is(closeErr.Errs[0], ErrPool) == false {
+				t.Fatal("expecting the error of the pool", err)
+			}
+
+			if len(Disposed) != 3 || Disposed[0] != "config" || Disposed[1] != "pool" || Disposed[2] != "logger" {
+				t.Fatal("expecting reverse dependency order", Disposed)
+			}
+
+			if expected == "" {
+				expected = err.Error()
+			} else if err.Error() != expected {
+				t.Fatal("expecting the same error", err, expected)
+			}
+
+			if err := resolver.Close(context.Background()); err != nil || len(Disposed) != 3 {
+				t.Fatal("expecting a second Close to do nothing", err)
+			}
+
+			errs := []*di.ErrResolve{
+				resolver.Resolve(&config),
+				resolver.Invoke(func(Store) {}),
+				resolver.Resolve(new(di.IResolver)),
+			}
+
+			for index, err := range errs {
+				if err == nil || err.Err != di.ErrClosed {
+					t.Fatal("expecting the resolver to be closed", index, err)
+				}
+			}
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest("GET", "/", nil))
+			if w.Code != http.StatusInternalServerError {
+				t.Fatal("expecting errFn to be called", w.Code)
+			}
+		})
+	})
+	t.Run("Lifecycle", func(t *testing.T) {
+		var expected string
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			ctx := context.Background()
+			Hooks = nil
+			if err := resolver.Start(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := resolver.Start(ctx); err != di.ErrStarted {
+				t.Fatal("expecting the resolver to be started", err)
+			}
+
+			if err := resolver.Stop(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			if strings.Join(Hooks, ",") != "pool.start,config.start,config.stop,pool.stop,logger.stop" {
+				t.Fatal("expecting hooks in dependency order", Hooks)
+			}
+
+			Hooks = nil
+			ConfigStartErr = errors.New("config")
+			defer func() { ConfigStartErr = nil }()
+
+			err := resolver.Start(ctx)
+			var lifecycleErr *di.ErrLifecycle
+			if errors.As(err, &lifecycleErr) == false || errors.Is(lifecycleErr.Errs[0], ConfigStartErr) == false {
+				t.Fatal("expecting the error of config", err)
+			}
+
+			if strings.Join(Hooks, ",") != "pool.start,config.start,pool.stop,logger.stop" {
+				t.Fatal("expecting started hooks to be stopped", Hooks)
+			}
+
+			if expected == "" {
+				expected = err.Error()
+			} else if err.Error() != expected {
+				t.Fatal("expecting the same error", err, expected)
+			}
+		})
+	})
+	t.Run("Definitions", func(t *testing.T) {
+		var expected string
+		forEachResolver(t, func(t *testing.T, resolver di.IHttpResolver) {
+			if len(resolver.Definitions()) != 8 {
+				t.Fatal("unexpected definitions", resolver.Definitions())
+			}
+
+			if _, err := resolver.HttpHandler(Index); err != nil {
+				t.Fatal(err)
+			}
+
+			buf := new(bytes.Buffer)
+			if err := resolver.WriteGraph(buf, di.GraphDOT); err != nil {
+				t.Fatal(err)
+			}
+
+			if expected == "" {
+				expected = buf.String()
+			} else if buf.String() != expected {
+				t.Fatal("expecting the same graph", buf.String())
+			}
+		})
+	})
+}
@@ -0,0 +1,1030 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/clavoie/di/v2"
+	"github.com/clavoie/di/v2/internal/static"
+)
+
+// iloggerKey is the key of di.ILogger
+const iloggerKey = static.DiPath + ".ILogger"
+
+// generator writes the source of a generated resolver
+type generator struct {
+	buf       bytes.Buffer
+	funcName  string
+	graph     *static.Graph
+	handlers  []*handler
+	imports   map[string]string
+	indexes   map[*static.Node]int
+	logger    *static.Node
+	pkg       *static.Package
+	usedNames map[string]string
+}
+
+// handler is an http handler signature the resolver is generated for
+type handler struct {
+	Deps      []*static.Dep
+	Signature *types.Signature
+}
+
+// generate returns the source of the resolver of the definitions in pkg.
+// An error is returned for each definition which cannot be generated
+func generate(pkg *static.Package, funcName string) ([]byte, error) {
+	if len(pkg.DefVars) == 0 {
+		return nil, fmt.Errorf("digen: no []*di.Def vars in package %v", pkg.Types.Path())
+	}
+
+	defs := make([]*static.Def, 0)
+	for _, defVar := range pkg.DefVars {
+		defs = append(defs, defVar.Defs...)
+	}
+
+	graph, errs := static.NewGraph(pkg, defs)
+	g := &generator{
+		funcName:  funcName,
+		graph:     graph,
+		imports:   make(map[string]string),
+		indexes:   make(map[*static.Node]int, len(graph.Nodes)),
+		logger:    graph.Node(iloggerKey),
+		pkg:       pkg,
+		usedNames: make(map[string]string),
+	}
+
+	errs = append(errs, g.verify()...)
+	if len(errs) > 0 {
+		static.SortErrs(errs)
+		msgs := make([]string, len(errs))
+		for index, err := range errs {
+			msgs[index] = err.Error()
+		}
+
+		return nil, errors.New(strings.Join(msgs, "\n"))
+	}
+
+	for index, node := range graph.Nodes {
+		g.indexes[node] = index
+	}
+
+	return g.source()
+}
+
+// verify returns an error for each definition or http handler which
+// cannot be generated
+func (g *generator) verify() []*static.ErrAt {
+	errs := make([]*static.ErrAt, 0)
+	errAt := func(def *static.Def, format string, args ...interface{}) {
+		errs = append(errs, &static.ErrAt{Err: fmt.Errorf(format, args...), Position: g.pkg.Position(def.Lit.Pos())})
+	}
+
+	for _, def := range g.graph.Skipped {
+		errAt(def, "digen: the constructor is not a func, annotated constructors are not supported")
+	}
+
+	for _, node := range g.graph.Nodes {
+		switch {
+		case node.Def.Func == nil:
+			errAt(node.Def, "digen: the constructor of %v must be a package level func", static.Name(node.Type))
+		case node.Unsupported != "":
+			errAt(node.Def, "digen: %v is not supported: %v", static.Name(node.Type), node.Unsupported)
+		}
+
+		for _, dep := range g.graph.Missing(node.Deps) {
+			errAt(node.Def, "di: definition missing for type: %v, a dependency of %v", static.Name(dep.Type), static.Name(node.Type))
+		}
+
+		if path := g.graph.CaptivePath(node); path != nil {
+			errAt(node.Def, "%v", static.CaptiveErr(path))
+		}
+	}
+
+	if cycle := g.graph.Cycle(); cycle != nil {
+		path := make([]string, len(cycle))
+		for index, node := range cycle {
+			path[index] = static.Name(node.Type)
+		}
+
+		errAt(cycle[0].Def, "di: circular dependency detected: %v", strings.Join(path, "->"))
+	}
+
+	seen := make(map[string]bool)
+	for _, httpDef := range g.pkg.HttpDefs {
+		if httpDef.Signature == nil {
+			continue
+		}
+
+		sig := unnamedSignature(httpDef.Signature)
+		deps, unsupported := static.NewHandlerDeps(sig)
+		key := static.Key(sig)
+		if unsupported != "" || seen[key] {
+			// injected using reflection
+			continue
+		}
+
+		seen[key] = true
+		for _, dep := range g.graph.Missing(deps) {
+			errs = append(errs, &static.ErrAt{
+				Err:      fmt.Errorf("di: definition missing for type: %v, a parameter of the handler of %q", static.Name(dep.Type), httpDef.Pattern),
+				Position: g.pkg.Position(httpDef.Lit.Pos()),
+			})
+		}
+
+		g.handlers = append(g.handlers, &handler{deps, sig})
+	}
+
+	return errs
+}
+
+// unnamedSignature returns sig without the names of its parameters and
+// results, so that it can be used as a case of a type switch
+func unnamedSignature(sig *types.Signature) *types.Signature {
+	unnamed := func(tuple *types.Tuple) *types.Tuple {
+		vars := make([]*types.Var, tuple.Len())
+		for index := range vars {
+			vars[index] = types.NewParam(token.NoPos, nil, "", tuple.At(index).Type())
+		}
+
+		return types.NewTuple(vars...)
+	}
+
+	return types.NewSignatureType(nil, nil, nil, unnamed(sig.Params()), unnamed(sig.Results()), sig.Variadic())
+}
+
+// printf writes a formatted line of source
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format+"\n", args...)
+}
+
+// use returns the name the package at path is imported as
+func (g *generator) use(path, name string) string {
+	if importName, isImported := g.imports[path]; isImported {
+		return importName
+	}
+
+	importName := name
+	for suffix := 1; g.usedNames[importName] != ""; suffix += 1 {
+		importName = fmt.Sprintf("%v%v", name, suffix)
+	}
+
+	g.imports[path] = importName
+	g.usedNames[importName] = path
+	return importName
+}
+
+// typeName returns the name of t in the generated source
+func (g *generator) typeName(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == g.pkg.Types {
+			return ""
+		}
+
+		return g.use(pkg.Path(), pkg.Name())
+	})
+}
+
+// funcRef returns the name of the constructor of node in the generated
+// source
+func (g *generator) funcRef(node *static.Node) string {
+	fn := node.Def.Func
+	if fn.Pkg() == g.pkg.Types {
+		return fn.Name()
+	}
+
+	return fmt.Sprintf("%v.%v", g.use(fn.Pkg().Path(), fn.Pkg().Name()), fn.Name())
+}
+
+// source returns the formatted source of the generated resolver
+func (g *generator) source() ([]byte, error) {
+	diPkg := g.use(static.DiPath, "di")
+	fmtPkg := g.use("fmt", "fmt")
+	httpPkg := g.use("net/http", "http")
+	reflectPkg := g.use("reflect", "reflect")
+
+	body := g.body(diPkg, fmtPkg, httpPkg, reflectPkg)
+
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	g.buf.Reset()
+	g.printf("// Code generated by digen. DO NOT EDIT.")
+	g.printf("")
+	g.printf("package %v", g.pkg.Types.Name())
+	g.printf("")
+	g.printf("import (")
+	for _, isStd := range []bool{true, false} {
+		g.printf("")
+		for _, path := range paths {
+			name := g.imports[path]
+
+			switch {
+			case strings.Contains(path, ".") == isStd:
+				continue
+			case name == path[strings.LastIndex(path, "/")+1:]:
+				g.printf("\t%q", path)
+			default:
+				g.printf("\t%v %q", name, path)
+			}
+		}
+	}
+	g.printf(")")
+	g.buf.WriteString(body)
+
+	return format.Source(g.buf.Bytes())
+}
+
+// body returns the source of the generated resolver after the imports.
+// diPkg, fmtPkg, httpPkg, and reflectPkg are the names of the packages
+func (g *generator) body(diPkg, fmtPkg, httpPkg, reflectPkg string) string {
+	g.buf.Reset()
+	errFnType := fmt.Sprintf("func(*%v.ErrResolve, %v.ResponseWriter, *%v.Request)", diPkg, httpPkg, httpPkg)
+
+	defVars := make([]string, len(g.pkg.DefVars))
+	for index, defVar := range g.pkg.DefVars {
+		defVars[index] = defVar.Name
+	}
+
+	g.printf("")
+	g.printf("// digenErrorType is typeof(error)")
+	g.printf("var digenErrorType = %v.TypeOf((*error)(nil)).Elem()", reflectPkg)
+	g.printf("")
+	g.printf("// digenRequestType and digenResponseWriterType are the types of the http request")
+	g.printf("var digenRequestType = %v.TypeOf((**%v.Request)(nil)).Elem()", reflectPkg, httpPkg)
+	g.printf("var digenResponseWriterType = %v.TypeOf((*%v.ResponseWriter)(nil)).Elem()", reflectPkg, httpPkg)
+	g.printf("")
+	g.printf("// digenTypes are the types of the definitions")
+	g.printf("var (")
+	for index, node := range g.graph.Nodes {
+		g.printf("\tdigenType%v = %v.TypeOf((*%v)(nil)).Elem()", index, reflectPkg, g.typeName(node.Type))
+	}
+	g.printf(")")
+	g.printf("")
+	g.printf("var _ %v.IHttpResolver = (*digenResolver)(nil)", diPkg)
+	g.printf("var _ %v.IResolver = (*digenScope)(nil)", diPkg)
+
+	g.printf("")
+	g.printf("// %v returns the same resolver as di.NewResolver(errFn, %v), except", g.funcName, strings.Join(defVars, ", "))
+	g.printf("// that the definitions are resolved by generated code instead of by reflection")
+	g.printf("func %v(errFn %v) (%v.IHttpResolver, error) {", g.funcName, errFnType, diPkg)
+	g.printf("\tmeta, err := %v.NewResolver(errFn, %v)", diPkg, strings.Join(defVars, ", "))
+	g.printf("\tif err != nil {")
+	g.printf("\t\treturn nil, err")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn &digenResolver{errFn: errFn, meta: meta}, nil")
+	g.printf("}")
+
+	g.printf("")
+	g.printf("// digenResolver is the generated resolver. Definitions and the graph are")
+	g.printf("// described by meta, a resolver of the same definitions which is never")
+	g.printf("// used to resolve a value")
+	g.printf("type digenResolver struct {")
+	g.printf("\tclosed int32")
+	g.printf("\terrFn %v", errFnType)
+	g.printf("\tmeta %v.IHttpResolver", diPkg)
+	g.printf("")
+	g.printf("\tlifecycleLock %v.Mutex", g.use("sync", "sync"))
+	g.printf("\tstarted []digenSingleton")
+	for index, node := range g.graph.Nodes {
+		if node.Def.Lifetime == di.Singleton {
+			g.printf("")
+			g.printf("\tsingletonLock%v %v.Mutex", index, g.use("sync", "sync"))
+			g.printf("\tsingleton%v %v", index, g.typeName(node.Type))
+			g.printf("\thasSingleton%v bool", index)
+		}
+	}
+	g.printf("}")
+
+	g.printf("")
+	g.printf("// digenScope resolves the values of one call of the resolver, or of one")
+	g.printf("// http request, and is injected as the di.IResolver")
+	g.printf("type digenScope struct {")
+	g.printf("\tclosables []%v.IHttpClosable", diPkg)
+	g.printf("\tisHttp bool")
+	g.printf("\tr *digenResolver")
+	g.printf("\treq *%v.Request", httpPkg)
+	g.printf("\tw %v.ResponseWriter", httpPkg)
+	for index, node := range g.graph.Nodes {
+		switch node.Def.Lifetime {
+		case di.PerHttpRequest, di.PerResolve:
+			g.printf("")
+			g.printf("\tvalue%v %v", index, g.typeName(node.Type))
+			g.printf("\thasValue%v bool", index)
+		}
+	}
+	g.printf("}")
+
+	g.resolverMethods(diPkg, httpPkg, reflectPkg)
+	g.scopeMethods(diPkg, fmtPkg, httpPkg, reflectPkg)
+
+	for _, node := range g.graph.Nodes {
+		g.resolveFunc(node, diPkg, reflectPkg)
+	}
+
+	return g.buf.String()
+}
+
+// resolverMethods writes the methods of digenResolver
+func (g *generator) resolverMethods(diPkg, httpPkg, reflectPkg string) {
+	atomicPkg := g.use("sync/atomic", "atomic")
+	contextPkg := g.use("context", "context")
+
+	singletons := make([]int, 0)
+	for _, node := range g.graph.DependencyOrder() {
+		if node.Def.Lifetime == di.Singleton {
+			singletons = append(singletons, g.indexes[node])
+		}
+	}
+
+	g.printf("")
+	g.printf("func (r *digenResolver) Close(ctx %v.Context) error {", contextPkg)
+	g.printf("\tif %v.SwapInt32(&r.closed, 1) == 1 {", atomicPkg)
+	g.printf("\t\treturn nil")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tr.lifecycleLock.Lock()")
+	g.printf("\terrs := digenStop(ctx, r.started)")
+	g.printf("\tr.started = nil")
+	g.printf("\tr.lifecycleLock.Unlock()")
+	g.printf("")
+	g.printf("\t// Singletons are disposed of in reverse dependency order")
+	g.printf("\tdisposables := make([]digenSingleton, 0)")
+	for orderIndex := len(singletons) - 1; orderIndex >= 0; orderIndex -= 1 {
+		index := singletons[orderIndex]
+		g.printf("\tr.singletonLock%v.Lock()", index)
+		g.printf("\tif r.hasSingleton%v {", index)
+		g.printf("\t\tdisposables = append(disposables, digenSingleton{r.singleton%v, digenType%v})", index, index)
+		g.printf("\t}")
+		g.printf("\tr.singletonLock%v.Unlock()", index)
+		g.printf("")
+	}
+	g.printf("\treturn digenDispose(ctx, errs, disposables)")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (r *digenResolver) Curry(fn interface{}) (interface{}, *%v.ErrResolve) {", diPkg)
+	g.printf("\treturn (&digenScope{r: r}).Curry(fn)")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (r *digenResolver) Definitions() []*%v.DefInfo {", diPkg)
+	g.printf("\treturn r.meta.Definitions()")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (r *digenResolver) HttpHandler(fn interface{}) (func(%v.ResponseWriter, *%v.Request), error) {", httpPkg, httpPkg)
+	g.printf("\t_, err := r.meta.HttpHandler(fn)")
+	g.printf("\tif err != nil {")
+	g.printf("\t\treturn nil, err")
+	g.printf("\t}")
+
+	if len(g.handlers) > 0 {
+		g.printf("")
+		g.printf("\tswitch typedFn := fn.(type) {")
+		for _, handler := range g.handlers {
+			g.printf("\tcase %v:", g.typeName(handler.Signature))
+			g.printf("\t\treturn r.handler(%v.TypeOf(fn), func(s *digenScope) (func(), *%v.ErrResolve) {", reflectPkg, diPkg)
+			args := g.deps("\t\t\t", "nil", handler.Deps, "nil, err", diPkg)
+			g.printf("\t\t\treturn func() { typedFn(%v) }, nil", strings.Join(args, ", "))
+			g.printf("\t\t}), nil")
+		}
+		g.printf("\t}")
+	}
+
+	g.printf("")
+	g.printf("\tfnValue := %v.ValueOf(fn)", reflectPkg)
+	g.printf("\treturn r.handler(fnValue.Type(), func(s *digenScope) (func(), *%v.ErrResolve) {", diPkg)
+	g.printf("\t\targs, err := s.resolveArgs(fnValue.Type())")
+	g.printf("\t\tif err != nil {")
+	g.printf("\t\t\treturn nil, err")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tif fnValue.Type().IsVariadic() {")
+	g.printf("\t\t\treturn func() { fnValue.CallSlice(args) }, nil")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\treturn func() { fnValue.Call(args) }, nil")
+	g.printf("\t}), nil")
+	g.printf("}")
+
+	g.printf("")
+	g.printf("func (r *digenResolver) Invoke(fn interface{}) *%v.ErrResolve {", diPkg)
+	g.printf("\treturn (&digenScope{r: r}).Invoke(fn)")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (r *digenResolver) Resolve(ptrToIface interface{}) *%v.ErrResolve {", diPkg)
+	g.printf("\treturn (&digenScope{r: r}).Resolve(ptrToIface)")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (r *digenResolver) ResolveNamed(name string, ptrToIface interface{}) *%v.ErrResolve {", diPkg)
+	g.printf("\treturn (&digenScope{r: r}).ResolveNamed(name, ptrToIface)")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (r *digenResolver) SetDefaultServeMux(httpDefs []*%v.HttpDef) error {", diPkg)
+	g.printf("\tfor _, httpDef := range httpDefs {")
+	g.printf("\t\tinjectedHandler, err := r.HttpHandler(httpDef.Handler)")
+	g.printf("\t\tif err != nil {")
+	g.printf("\t\t\treturn err")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\t%v.HandleFunc(httpDef.Pattern, injectedHandler)", httpPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn nil")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (r *digenResolver) Start(ctx %v.Context) error {", contextPkg)
+	g.printf("\tr.lifecycleLock.Lock()")
+	g.printf("\tdefer r.lifecycleLock.Unlock()")
+	g.printf("")
+	g.printf("\tif r.isClosed() {")
+	g.printf("\t\treturn %v.ErrClosed", diPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tif r.started != nil {")
+	g.printf("\t\treturn %v.ErrStarted", diPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\t// Singletons are started in dependency order")
+	if len(singletons) > 0 {
+		g.printf("\ts := &digenScope{r: r}")
+	}
+	g.printf("\tresolves := []func() (interface{}, *%v.ErrResolve){", diPkg)
+	for _, index := range singletons {
+		g.printf("\t\tfunc() (interface{}, *%v.ErrResolve) {", diPkg)
+		g.printf("\t\t\tvalue, err := s.resolve%v(nil)", index)
+		g.printf("\t\t\treturn value, err")
+		g.printf("\t\t},")
+	}
+	g.printf("\t}")
+	types := make([]string, len(singletons))
+	for typeIndex, index := range singletons {
+		types[typeIndex] = fmt.Sprintf("digenType%v", index)
+	}
+	g.printf("\ttypes := []%v.Type{%v}", reflectPkg, strings.Join(types, ", "))
+	g.printf("")
+	g.printf("\tseen := make(map[interface{}]bool)")
+	g.printf("\tstarted := make([]digenSingleton, 0)")
+	g.printf("\tfor index, resolve := range resolves {")
+	g.printf("\t\tvalue, err := resolve()")
+	g.printf("\t\tif err != nil {")
+	g.printf("\t\t\treturn digenRollback(ctx, started, err)")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tswitch value.(type) {")
+	g.printf("\t\tcase %v.IStartable, %v.IStoppable:", diPkg, diPkg)
+	g.printf("\t\tdefault:")
+	g.printf("\t\t\tcontinue")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tif %v.TypeOf(value).Comparable() {", reflectPkg)
+	g.printf("\t\t\tif seen[value] {")
+	g.printf("\t\t\t\tcontinue")
+	g.printf("\t\t\t}")
+	g.printf("")
+	g.printf("\t\t\tseen[value] = true")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tif startable, isStartable := value.(%v.IStartable); isStartable {", diPkg)
+	g.printf("\t\t\tif err := digenRunHook(ctx, startable.Di_Start); err != nil {")
+	g.printf("\t\t\t\treturn digenRollback(ctx, started, %v.Errorf(\"di: starting %%v: %%w\", types[index], err))", g.use("fmt", "fmt"))
+	g.printf("\t\t\t}")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tstarted = append(started, digenSingleton{value, types[index]})")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tr.started = started")
+	g.printf("\treturn nil")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (r *digenResolver) Stop(ctx %v.Context) error {", contextPkg)
+	g.printf("\tr.lifecycleLock.Lock()")
+	g.printf("\tdefer r.lifecycleLock.Unlock()")
+	g.printf("")
+	g.printf("\terrs := digenStop(ctx, r.started)")
+	g.printf("\tr.started = nil")
+	g.printf("\tif len(errs) > 0 {")
+	g.printf("\t\treturn &%v.ErrLifecycle{Errs: errs}", diPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn nil")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (r *digenResolver) WriteGraph(w %v.Writer, format %v.GraphFormat) error {", g.use("io", "io"), diPkg)
+	g.printf("\treturn r.meta.WriteGraph(w, format)")
+	g.printf("}")
+	g.printf("")
+	g.printf("// isClosed returns true if Close has been called on the resolver")
+	g.printf("func (r *digenResolver) isClosed() bool {")
+	g.printf("\treturn %v.LoadInt32(&r.closed) == 1", atomicPkg)
+	g.printf("}")
+
+	g.printf("")
+	g.printf("// handler returns an http handler which resolves the dependencies of a")
+	g.printf("// handler func of fnType with resolve, and then calls the func")
+	g.printf("func (r *digenResolver) handler(fnType %v.Type, resolve func(*digenScope) (func(), *%v.ErrResolve)) func(%v.ResponseWriter, *%v.Request) {", reflectPkg, diPkg, httpPkg, httpPkg)
+	g.printf("\treturn func(w %v.ResponseWriter, req *%v.Request) {", httpPkg, httpPkg)
+	if g.logger != nil {
+		g.printf("\t\tepoch := %v.Now()", g.use("time", "time"))
+	}
+	g.printf("\t\tif r.isClosed() {")
+	g.printf("\t\t\tr.errFn(digenErr(nil, %v.ErrClosed, fnType), w, req)", diPkg)
+	g.printf("\t\t\treturn")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\ts := &digenScope{isHttp: true, r: r, req: req, w: w}")
+	g.printf("\t\tcall, err := resolve(s)")
+	g.printf("\t\tif err != nil {")
+	g.printf("\t\t\tr.errFn(err, w, req)")
+	g.printf("\t\t\treturn")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tfor _, closable := range s.closables {")
+	g.printf("\t\t\tdefer closable.Di_HttpClose()")
+	g.printf("\t\t}")
+	if g.logger != nil {
+		g.printf("")
+		g.printf("\t\tduration := %v.Since(epoch)", g.use("time", "time"))
+		g.printf("\t\tlogger, err := s.resolve%v(nil)", g.indexes[g.logger])
+		g.printf("\t\tif err != nil {")
+		g.printf("\t\t\tr.errFn(err, w, req)")
+		g.printf("\t\t\treturn")
+		g.printf("\t\t}")
+		g.printf("")
+		g.printf("\t\tlogger.HttpDuration(duration)")
+	}
+	g.printf("")
+	g.printf("\t\tcall()")
+	g.printf("\t}")
+	g.printf("}")
+
+	g.printf("")
+	g.printf("// digenSingleton is a Singleton to start, stop, or dispose of")
+	g.printf("type digenSingleton struct {")
+	g.printf("\tvalue interface{}")
+	g.printf("\trtype %v.Type", reflectPkg)
+	g.printf("}")
+	g.printf("")
+	g.printf("// digenRunHook calls hook, returning the error of ctx instead if ctx is")
+	g.printf("// done first")
+	g.printf("func digenRunHook(ctx %v.Context, hook func(%v.Context) error) error {", contextPkg, contextPkg)
+	g.printf("\tif ctx.Err() != nil {")
+	g.printf("\t\treturn ctx.Err()")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tdone := make(chan error, 1)")
+	g.printf("\tgo func() {")
+	g.printf("\t\tdone <- hook(ctx)")
+	g.printf("\t}()")
+	g.printf("")
+	g.printf("\tselect {")
+	g.printf("\tcase err := <-done:")
+	g.printf("\t\treturn err")
+	g.printf("\tcase <-ctx.Done():")
+	g.printf("\t\treturn ctx.Err()")
+	g.printf("\t}")
+	g.printf("}")
+	g.printf("")
+	g.printf("// digenStop calls Di_Stop on each of started which is a di.IStoppable, in")
+	g.printf("// reverse order, returning every error")
+	g.printf("func digenStop(ctx %v.Context, started []digenSingleton) []error {", contextPkg)
+	g.printf("\terrs := make([]error, 0)")
+	g.printf("\tfor index := len(started) - 1; index >= 0; index -= 1 {")
+	g.printf("\t\tstoppable, isStoppable := started[index].value.(%v.IStoppable)", diPkg)
+	g.printf("\t\tif isStoppable == false {")
+	g.printf("\t\t\tcontinue")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tif err := digenRunHook(ctx, stoppable.Di_Stop); err != nil {")
+	g.printf("\t\t\terrs = append(errs, %v.Errorf(\"di: stopping %%v: %%w\", started[index].rtype, err))", g.use("fmt", "fmt"))
+	g.printf("\t\t}")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn errs")
+	g.printf("}")
+	g.printf("")
+	g.printf("// digenRollback stops started after err stopped the resolver from starting")
+	g.printf("func digenRollback(ctx %v.Context, started []digenSingleton, err error) error {", contextPkg)
+	g.printf("\treturn &%v.ErrLifecycle{Errs: append([]error{err}, digenStop(ctx, started)...)}", diPkg)
+	g.printf("}")
+	g.printf("")
+	g.printf("// digenDispose disposes of each value which is a di.IDisposable or an")
+	g.printf("// io.Closer, in order, returning errs and every error in a *di.ErrClose")
+	g.printf("func digenDispose(ctx %v.Context, errs []error, disposables []digenSingleton) error {", contextPkg)
+	g.printf("\tdisposed := make(map[interface{}]bool)")
+	g.printf("\tfor _, disposable := range disposables {")
+	g.printf("\t\tswitch disposable.value.(type) {")
+	g.printf("\t\tcase %v.IDisposable, %v.Closer:", diPkg, g.use("io", "io"))
+	g.printf("\t\tdefault:")
+	g.printf("\t\t\tcontinue")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tif %v.TypeOf(disposable.value).Comparable() {", reflectPkg)
+	g.printf("\t\t\tif disposed[disposable.value] {")
+	g.printf("\t\t\t\tcontinue")
+	g.printf("\t\t\t}")
+	g.printf("")
+	g.printf("\t\t\tdisposed[disposable.value] = true")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tif ctx.Err() != nil {")
+	g.printf("\t\t\terrs = append(errs, ctx.Err())")
+	g.printf("\t\t\tbreak")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tvar err error")
+	g.printf("\t\tswitch value := disposable.value.(type) {")
+	g.printf("\t\tcase %v.IDisposable:", diPkg)
+	g.printf("\t\t\terr = value.Di_Dispose(ctx)")
+	g.printf("\t\tcase %v.Closer:", g.use("io", "io"))
+	g.printf("\t\t\terr = value.Close()")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tif err != nil {")
+	g.printf("\t\t\terrs = append(errs, %v.Errorf(\"di: closing %%v: %%w\", disposable.rtype, err))", g.use("fmt", "fmt"))
+	g.printf("\t\t}")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tif len(errs) > 0 {")
+	g.printf("\t\treturn &%v.ErrClose{Errs: errs}", diPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn nil")
+	g.printf("}")
+}
+
+// scopeMethods writes the methods of digenScope
+func (g *generator) scopeMethods(diPkg, fmtPkg, httpPkg, reflectPkg string) {
+	g.printf("")
+	g.printf("func (s *digenScope) Curry(fn interface{}) (interface{}, *%v.ErrResolve) {", diPkg)
+	g.printf("\tfnValue := %v.ValueOf(fn)", reflectPkg)
+	g.printf("\tif fnValue.Kind() != %v.Func {", reflectPkg)
+	g.printf("\t\treturn nil, digenErr(nil, %v.Errorf(\"di: the generated resolver only injects plain funcs, annotated funcs are not supported: %%T\", fn), %v.TypeOf(fn))", fmtPkg, reflectPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tfnType := fnValue.Type()")
+	g.printf("\tnumIn := fnType.NumIn()")
+	g.printf("\tisVariadic := fnType.IsVariadic()")
+	g.printf("\tknowns := make([]%v.Value, numIn)", reflectPkg)
+	g.printf("\tcallTypes := make([]%v.Type, 0, numIn)", reflectPkg)
+	g.printf("\tfor index := 0; index < numIn; index += 1 {")
+	g.printf("\t\tinType := fnType.In(index)")
+	g.printf("\t\tif index == numIn-1 && isVariadic {")
+	g.printf("\t\t\tcallTypes = append(callTypes, inType)")
+	g.printf("\t\t\tcontinue")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tvalue, err := s.resolveArg(nil, inType)")
+	g.printf("\t\tif err != nil {")
+	g.printf("\t\t\tif _, isDefMissing := err.Err.(*%v.ErrDefMissing); isDefMissing {", diPkg)
+	g.printf("\t\t\t\tcallTypes = append(callTypes, inType)")
+	g.printf("\t\t\t\tcontinue")
+	g.printf("\t\t\t}")
+	g.printf("")
+	g.printf("\t\t\treturn nil, err")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tknowns[index] = value")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\toutTypes := make([]%v.Type, fnType.NumOut())", reflectPkg)
+	g.printf("\tfor index := range outTypes {")
+	g.printf("\t\toutTypes[index] = fnType.Out(index)")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tcurryFnType := %v.FuncOf(callTypes, outTypes, isVariadic)", reflectPkg)
+	g.printf("\treturn %v.MakeFunc(curryFnType, func(ins []%v.Value) []%v.Value {", reflectPkg, reflectPkg, reflectPkg)
+	g.printf("\t\tcallVals := make([]%v.Value, numIn)", reflectPkg)
+	g.printf("\t\tcallIndex := 0")
+	g.printf("\t\tfor index := range callVals {")
+	g.printf("\t\t\tif knowns[index].IsValid() {")
+	g.printf("\t\t\t\tcallVals[index] = knowns[index]")
+	g.printf("\t\t\t} else {")
+	g.printf("\t\t\t\tcallVals[index] = ins[callIndex]")
+	g.printf("\t\t\t\tcallIndex += 1")
+	g.printf("\t\t\t}")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tif isVariadic {")
+	g.printf("\t\t\treturn fnValue.CallSlice(callVals)")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\treturn fnValue.Call(callVals)")
+	g.printf("\t}).Interface(), nil")
+	g.printf("}")
+
+	g.printf("")
+	g.printf("func (s *digenScope) Invoke(fn interface{}) *%v.ErrResolve {", diPkg)
+	g.printf("\tnewFn, err := s.Curry(fn)")
+	g.printf("\tif err != nil {")
+	g.printf("\t\treturn err")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tfnValue := %v.ValueOf(newFn)", reflectPkg)
+	g.printf("\tfnType := fnValue.Type()")
+	g.printf("\tif fnType.NumIn() > 0 {")
+	g.printf("\t\treturn digenErr(nil, %v.Errorf(\"di: Invoke: cannot invoke a func with input parameters: %%v\", fnType.NumIn()), fnType)", fmtPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\toutValues := fnValue.Call(nil)")
+	g.printf("\tif fnType.NumOut() == 1 && fnType.Out(0) == digenErrorType {")
+	g.printf("\t\tif errVal := outValues[0].Interface(); errVal != nil {")
+	g.printf("\t\t\treturn digenErr(nil, errVal.(error), fnType)")
+	g.printf("\t\t}")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn nil")
+	g.printf("}")
+
+	g.printf("")
+	g.printf("func (s *digenScope) Resolve(ptrToIface interface{}) *%v.ErrResolve {", diPkg)
+	g.printf("\treturn s.ResolveNamed(\"\", ptrToIface)")
+	g.printf("}")
+	g.printf("")
+	g.printf("func (s *digenScope) ResolveNamed(name string, ptrToIface interface{}) *%v.ErrResolve {", diPkg)
+	g.printf("\tif ptrValue := %v.ValueOf(ptrToIface); s.r.isClosed() && ptrValue.Kind() == %v.Ptr {", reflectPkg, reflectPkg)
+	g.printf("\t\treturn digenErr(nil, %v.ErrClosed, ptrValue.Type().Elem())", diPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tif name == \"\" {")
+	g.printf("\t\tswitch ptr := ptrToIface.(type) {")
+	g.printf("\t\tcase *%v.IResolver:", diPkg)
+	g.printf("\t\t\t*ptr = s")
+	g.printf("\t\t\treturn nil")
+	for index, node := range g.graph.Nodes {
+		g.printf("\t\tcase *%v:", g.typeName(node.Type))
+		g.printf("\t\t\tvalue, err := s.resolve%v(nil)", index)
+		g.printf("\t\t\tif err != nil {")
+		g.printf("\t\t\t\treturn err")
+		g.printf("\t\t\t}")
+		g.printf("")
+		g.printf("\t\t\t*ptr = value")
+		g.printf("\t\t\treturn nil")
+	}
+	g.printf("\t\t}")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tptrValue := %v.ValueOf(ptrToIface)", reflectPkg)
+	g.printf("\tif ptrValue.Kind() != %v.Ptr {", reflectPkg)
+	g.printf("\t\treturn digenErr(nil, %v.Errorf(\"di: ptrToIFace must be a pointer type: %%v\", ptrValue.Type()), ptrValue.Type())", fmtPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tif name != \"\" {")
+	g.printf("\t\treturn digenErr(nil, &%v.ErrDefMissing{Name: name, Type: ptrValue.Type().Elem()}, ptrValue.Type().Elem())", diPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tvalue, err := s.resolveArg(nil, ptrValue.Type().Elem())")
+	g.printf("\tif err != nil {")
+	g.printf("\t\treturn err")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tptrValue.Elem().Set(value)")
+	g.printf("\treturn nil")
+	g.printf("}")
+
+	g.printf("")
+	g.printf("// resolveArg resolves a value of rtype, which is either a definition or")
+	g.printf("// supplied by the resolver")
+	g.printf("func (s *digenScope) resolveArg(depChain []%v.Type, rtype %v.Type) (%v.Value, *%v.ErrResolve) {", reflectPkg, reflectPkg, reflectPkg, diPkg)
+	g.printf("\tvar value interface{}")
+	g.printf("\tvar err *%v.ErrResolve", diPkg)
+	g.printf("")
+	g.printf("\tswitch rtype {")
+	g.printf("\tcase digenRequestType:")
+	g.printf("\t\tvalue, err = s.request(depChain)")
+	g.printf("\tcase digenResponseWriterType:")
+	g.printf("\t\tvalue, err = s.responseWriter(depChain)")
+	for index := range g.graph.Nodes {
+		g.printf("\tcase digenType%v:", index)
+		g.printf("\t\tvalue, err = s.resolve%v(depChain)", index)
+	}
+	g.printf("\tdefault:")
+	g.printf("\t\tif rtype == %v.TypeOf((*%v.IResolver)(nil)).Elem() {", reflectPkg, diPkg)
+	g.printf("\t\t\treturn %v.ValueOf(s), nil", reflectPkg)
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\tif unsupportedErr := digenUnsupported(rtype); unsupportedErr != nil {")
+	g.printf("\t\t\treturn %v.Value{}, digenErr(depChain, unsupportedErr, rtype)", reflectPkg)
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\treturn %v.Value{}, digenErr(depChain, &%v.ErrDefMissing{Type: rtype}, rtype)", reflectPkg, diPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tif err != nil {")
+	g.printf("\t\treturn %v.Value{}, err", reflectPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tif value == nil {")
+	g.printf("\t\treturn %v.Zero(rtype), nil", reflectPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn %v.ValueOf(value), nil", reflectPkg)
+	g.printf("}")
+
+	g.printf("")
+	g.printf("// resolveArgs resolves a value for each parameter of fnType")
+	g.printf("func (s *digenScope) resolveArgs(fnType %v.Type) ([]%v.Value, *%v.ErrResolve) {", reflectPkg, reflectPkg, diPkg)
+	g.printf("\targs := make([]%v.Value, fnType.NumIn())", reflectPkg)
+	g.printf("\tfor index := range args {")
+	g.printf("\t\targ, err := s.resolveArg(nil, fnType.In(index))")
+	g.printf("\t\tif err != nil {")
+	g.printf("\t\t\treturn nil, err")
+	g.printf("\t\t}")
+	g.printf("")
+	g.printf("\t\targs[index] = arg")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn args, nil")
+	g.printf("}")
+
+	g.printf("")
+	g.printf("// request returns the request the scope was created for")
+	g.printf("func (s *digenScope) request(depChain []%v.Type) (*%v.Request, *%v.ErrResolve) {", reflectPkg, httpPkg, diPkg)
+	g.printf("\tif s.isHttp == false {")
+	g.printf("\t\treturn nil, digenErr(depChain, &%v.ErrDefMissing{Type: digenRequestType}, digenRequestType)", diPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn s.req, nil")
+	g.printf("}")
+	g.printf("")
+	g.printf("// responseWriter returns the response writer of the request the scope was")
+	g.printf("// created for")
+	g.printf("func (s *digenScope) responseWriter(depChain []%v.Type) (%v.ResponseWriter, *%v.ErrResolve) {", reflectPkg, httpPkg, diPkg)
+	g.printf("\tif s.isHttp == false {")
+	g.printf("\t\treturn nil, digenErr(depChain, &%v.ErrDefMissing{Type: digenResponseWriterType}, digenResponseWriterType)", diPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn s.w, nil")
+	g.printf("}")
+	g.printf("")
+	g.printf("// closable records value to be closed when the http request of the scope")
+	g.printf("// completes, if it is a di.IHttpClosable")
+	g.printf("func (s *digenScope) closable(value interface{}) {")
+	g.printf("\tif closable, isClosable := value.(%v.IHttpClosable); isClosable {", diPkg)
+	g.printf("\t\ts.closables = append(s.closables, closable)")
+	g.printf("\t}")
+	g.printf("}")
+	g.printf("")
+	g.printf("// digenDefined returns true if rtype has a definition, or is supplied by")
+	g.printf("// the resolver itself")
+	g.printf("func digenDefined(rtype %v.Type) bool {", reflectPkg)
+	g.printf("\tswitch rtype {")
+	cases := []string{"digenRequestType", "digenResponseWriterType"}
+	for index := range g.graph.Nodes {
+		cases = append(cases, fmt.Sprintf("digenType%v", index))
+	}
+	g.printf("\tcase %v:", strings.Join(cases, ", "))
+	g.printf("\t\treturn true")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn rtype == %v.TypeOf((*%v.IResolver)(nil)).Elem()", reflectPkg, diPkg)
+	g.printf("}")
+	g.printf("")
+	g.printf("// digenUnsupported returns an error if rtype is a provider of a defined")
+	g.printf("// type or a parameter object, which the generated resolver does not inject")
+	g.printf("func digenUnsupported(rtype %v.Type) error {", reflectPkg)
+	g.printf("\tif rtype.Kind() == %v.Func && rtype.NumIn() == 0 && rtype.NumOut() == 2 && rtype.Out(1) == digenErrorType && rtype.IsVariadic() == false && digenDefined(rtype.Out(0)) {", reflectPkg)
+	g.printf("\t\treturn %v.Errorf(\"di: the generated resolver cannot inject a provider into a func it did not generate: %%v\", rtype)", fmtPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tif rtype.Kind() != %v.Struct {", reflectPkg)
+	g.printf("\t\treturn nil")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\tfor index := 0; index < rtype.NumField(); index += 1 {")
+	g.printf("\t\tif field := rtype.Field(index); field.Anonymous && field.Type == %v.TypeOf(%v.In{}) {", reflectPkg, diPkg)
+	g.printf("\t\t\treturn %v.Errorf(\"di: the generated resolver cannot inject a parameter object into a func it did not generate: %%v\", rtype)", fmtPkg)
+	g.printf("\t\t}")
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn nil")
+	g.printf("}")
+	g.printf("")
+	g.printf("// digenErr returns a new *di.ErrResolve")
+	g.printf("func digenErr(depChain []%v.Type, err error, rtype %v.Type) *%v.ErrResolve {", reflectPkg, reflectPkg, diPkg)
+	g.printf("\tif depChain == nil {")
+	g.printf("\t\tdepChain = make([]%v.Type, 0, 1)", reflectPkg)
+	g.printf("\t}")
+	g.printf("")
+	g.printf("\treturn &%v.ErrResolve{DependencyChain: depChain, Err: err, Type: rtype}", diPkg)
+	g.printf("}")
+}
+
+// deps writes the resolution of each of deps to a local var, returning the
+// names of the vars. depChain is the chain of the dependent, and errReturn
+// the values returned on an error
+func (g *generator) deps(indent, depChain string, deps []*static.Dep, errReturn, diPkg string) []string {
+	args := make([]string, len(deps))
+
+	for index, dep := range deps {
+		arg := fmt.Sprintf("p%v", index)
+		args[index] = arg
+
+		var resolve string
+		switch {
+		case static.IsResolver(dep.Type):
+			g.printf("%v%v := %v.IResolver(s)", indent, arg, diPkg)
+			g.printf("")
+			continue
+		case static.IsRequest(dep.Type):
+			resolve = "s.request"
+		case static.IsResponseWriter(dep.Type):
+			resolve = "s.responseWriter"
+		default:
+			resolve = fmt.Sprintf("s.resolve%v", g.indexes[g.graph.Node(dep.Key)])
+		}
+
+		if dep.Provider {
+			g.printf("%v%v := func() (%v, error) {", indent, arg, g.typeName(dep.Type))
+			g.printf("%v\tvalue, err := %v(%v)", indent, resolve, depChain)
+			g.printf("%v\tif err != nil {", indent)
+			g.printf("%v\t\treturn value, err", indent)
+			g.printf("%v\t}", indent)
+			g.printf("")
+			g.printf("%v\treturn value, nil", indent)
+			g.printf("%v}", indent)
+			g.printf("")
+			continue
+		}
+
+		g.printf("%v%v, err := %v(%v)", indent, arg, resolve, depChain)
+		g.printf("%vif err != nil {", indent)
+		g.printf("%v\treturn %v", indent, errReturn)
+		g.printf("%v}", indent)
+		g.printf("")
+	}
+
+	return args
+}
+
+// resolveFunc writes the func which resolves the value of node
+func (g *generator) resolveFunc(node *static.Node, diPkg, reflectPkg string) {
+	index := g.indexes[node]
+	lifetime := node.Def.Lifetime
+
+	g.printf("")
+	g.printf("// resolve%v resolves %v, a %v constructed by %v", index, static.Name(node.Type), lifetime, node.Def.Func.Name())
+	g.printf("func (s *digenScope) resolve%v(depChain []%v.Type) (value %v, err *%v.ErrResolve) {", index, reflectPkg, g.typeName(node.Type), diPkg)
+
+	if lifetime == di.Singleton {
+		g.printf("\ts.r.singletonLock%v.Lock()", index)
+		g.printf("\tdefer s.r.singletonLock%v.Unlock()", index)
+	}
+
+	g.printf("\tif s.r.isClosed() {")
+	g.printf("\t\treturn value, digenErr(depChain, %v.ErrClosed, digenType%v)", diPkg, index)
+	g.printf("\t}")
+	g.printf("")
+
+	switch lifetime {
+	case di.Singleton:
+		g.printf("\tif s.r.hasSingleton%v {", index)
+		g.printf("\t\treturn s.r.singleton%v, nil", index)
+		g.printf("\t}")
+		g.printf("")
+	case di.PerHttpRequest, di.PerResolve:
+		g.printf("\tif s.hasValue%v {", index)
+		g.printf("\t\treturn s.value%v, nil", index)
+		g.printf("\t}")
+		g.printf("")
+	}
+
+	for _, dep := range node.Deps {
+		if static.IsResolver(dep.Type) == false {
+			g.printf("\tchildDepChain := append(depChain[:len(depChain):len(depChain)], digenType%v)", index)
+			break
+		}
+	}
+
+	args := g.deps("\t", "childDepChain", node.Deps, "value, err", diPkg)
+
+	call := fmt.Sprintf("%v(%v)", g.funcRef(node), strings.Join(args, ", "))
+	if node.HasErr {
+		g.printf("\tvalue, ctorErr := %v", call)
+		g.printf("\tif ctorErr != nil {")
+		g.printf("\t\treturn value, digenErr(depChain, ctorErr, digenType%v)", index)
+		g.printf("\t}")
+	} else {
+		g.printf("\tvalue = %v", call)
+	}
+
+	g.printf("")
+	g.printf("\ts.closable(value)")
+	switch lifetime {
+	case di.Singleton:
+		g.printf("\ts.r.singleton%v, s.r.hasSingleton%v = value, true", index, index)
+	case di.PerHttpRequest, di.PerResolve:
+		g.printf("\ts.value%v, s.hasValue%v = value, true", index, index)
+	}
+
+	g.printf("\treturn value, nil")
+	g.printf("}")
+}
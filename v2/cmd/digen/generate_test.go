@@ -0,0 +1,121 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/clavoie/di/v2/internal/static"
+)
+
+func TestGenerate(t *testing.T) {
+	t.Run("Example", func(t *testing.T) {
+		pkg, err := static.Load("example", []string{"di_gen.go"}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		source, err := generate(pkg, "NewGeneratedResolver")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected, err := os.ReadFile(filepath.Join("example", "di_gen.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(source) != string(expected) {
+			t.Fatal("example/di_gen.go is out of date, run go generate ./cmd/digen/example")
+		}
+	})
+	t.Run("GenerateFile", func(t *testing.T) {
+		// the package must be in the module to import the di package
+		dir, err := os.MkdirTemp("testdata", "generate")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		for _, name := range []string{"defs.go", "di_gen.go"} {
+			source, err := os.ReadFile(filepath.Join("example", name))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if name == "di_gen.go" {
+				// a stale generated file is left out
+				source = []byte("package example\n\nfunc broken() { undefined() }\n")
+			}
+
+			err = os.WriteFile(filepath.Join(dir, name), source, 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err = os.WriteFile(filepath.Join(dir, "main.go"), []byte("package example\n\nvar _ = NewResolver\n"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = generateFile(dir, "di_gen.go", "NewResolver")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		source, err := os.ReadFile(filepath.Join(dir, "di_gen.go"))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(string(source), "func NewResolver(errFn") == false {
+			t.Fatal("expecting generated func", string(source))
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		pkg, err := static.Load(filepath.Join("testdata", "invalid"), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = generate(pkg, "NewGeneratedResolver")
+		if err == nil {
+			t.Fatal("expecting error")
+		}
+
+		expected := []string{
+			"invalid.go:26:2: di: circular dependency detected: invalid.A->invalid.B->invalid.A",
+			"invalid.go:28:2: di: definition missing for type: invalid.Missing, a dependency of invalid.C",
+			"invalid.go:29:2: di: unknown lifetime: Lifetime(10)",
+			"invalid.go:30:2: di: Singleton invalid.D captures a dependency with a shorter lifetime: invalid.D->*http.Request",
+			"invalid.go:31:2: di: a dependency for invalid.D already exists with a different constructor",
+			"invalid.go:32:2: digen: the constructor is not a func, annotated constructors are not supported",
+			"invalid.go:33:2: digen: the constructor of invalid.F must be a package level func",
+			"invalid.go:37:2: di: definition missing for type: invalid.Missing, a parameter of the handler of \"/\"",
+		}
+
+		msgs := strings.Split(err.Error(), "\n")
+		if len(msgs) != len(expected) {
+			t.Fatal("unexpected errors", err)
+		}
+
+		for index, msg := range msgs {
+			if strings.HasSuffix(msg, expected[index]) == false {
+				t.Fatal("unexpected error", msg, expected[index])
+			}
+		}
+	})
+	t.Run("NoDefs", func(t *testing.T) {
+		pkg, err := static.Load(filepath.Join("testdata", "nodefs"), nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = generate(pkg, "NewGeneratedResolver")
+		if err == nil {
+			t.Fatal("expecting error")
+		}
+	})
+}
@@ -0,0 +1,98 @@
+// Command digen generates a resolver for the definitions of a package which
+// resolves them without reflection.
+//
+// digen reads the package level []*di.Def vars of a package, and writes a
+// file to the package with a func that returns an IHttpResolver for those
+// definitions:
+//
+//	var Defs = []*di.Def{
+//		{NewStore, di.Singleton},
+//		{NewUserService, di.PerHttpRequest},
+//	}
+//
+//	//go:generate go run github.com/clavoie/di/v2/cmd/digen
+//
+//	resolver, err := NewGeneratedResolver(errFn)
+//
+// The generated resolver calls each constructor directly, and caches values
+// with the same Lifetime semantics as di.NewResolver, so the two resolvers
+// can be switched between. The handlers of the []*di.HttpDef literals in the
+// package are generated as well, other http handlers and the funcs passed to
+// Curry and Invoke have their parameters injected using reflection.
+//
+// The parameters injected using reflection may only be definitions, or
+// values supplied by the resolver such as di.IResolver. Providers,
+// parameter objects and annotated funcs, such as di.Params(...), are not
+// supported there, and return an *di.ErrResolve saying so instead of being
+// injected the way di.NewResolver would inject them.
+//
+// The generated resolver is a partial fast path: only resolving definitions
+// and calling the generated handlers skip reflection. The generated func
+// still calls di.NewResolver with the same definitions, and the resolver it
+// returns validates the definitions at startup and each handler passed to
+// HttpHandler, and implements Definitions and WriteGraph.
+//
+// Errors which NewResolver would return, such as a dependency which has no
+// definition or a circular dependency, are reported by digen with the
+// file:line of the definition instead. Only plain constructor funcs are
+// supported, a definition with an annotated constructor, such as
+// di.Named(...), or with a parameter object is reported as an error.
+//
+// Usage:
+//
+//	digen [-dir dir] [-o file] [-func name]
+//
+// The flags are:
+//
+//	-dir
+//		the directory of the package, defaults to the current directory
+//	-o
+//		the name of the generated file, defaults to di_gen.go
+//	-func
+//		the name of the generated func, defaults to NewGeneratedResolver
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/clavoie/di/v2/internal/static"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "the directory of the package")
+	out := flag.String("o", "di_gen.go", "the name of the generated file")
+	funcName := flag.String("func", "NewGeneratedResolver", "the name of the generated func")
+	flag.Parse()
+
+	err := generateFile(*dir, *out, *funcName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// generateFile generates the resolver of the package in dir, and writes
+// it to the file out in dir
+func generateFile(dir, out, funcName string) error {
+	out = filepath.Base(out)
+	pkg, err := static.Load(dir, []string{out}, func(err types.Error) bool {
+		// the generated func is referenced by the package, but is not
+		// defined while the generated file is left out
+		return strings.HasSuffix(err.Msg, "undefined: "+funcName)
+	})
+	if err != nil {
+		return err
+	}
+
+	source, err := generate(pkg, funcName)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(dir, out), source, 0644)
+}
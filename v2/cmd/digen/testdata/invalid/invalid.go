@@ -0,0 +1,38 @@
+package invalid
+
+import (
+	"net/http"
+
+	"github.com/clavoie/di/v2"
+)
+
+type A interface{}
+type B interface{}
+type C interface{}
+type D interface{}
+type E interface{}
+type F interface{}
+type Missing interface{}
+
+func NewA(b B) A                   { return nil }
+func NewB(a A) B                   { return nil }
+func NewC(missing Missing) C       { return nil }
+func NewD(r *http.Request) D       { return nil }
+func NewDErr() (D, error)          { return nil, nil }
+func NewE() E                      { return nil }
+func Handler(missing Missing, c C) {}
+
+var Defs = []*di.Def{
+	{Constructor: NewA, Lifetime: di.PerDependency},
+	{Constructor: NewB, Lifetime: di.PerDependency},
+	{Constructor: NewC, Lifetime: di.PerDependency},
+	{Constructor: NewE, Lifetime: di.Lifetime(10)},
+	{Constructor: NewD, Lifetime: di.Singleton},
+	{Constructor: NewDErr, Lifetime: di.Singleton},
+	{Constructor: di.Named("e", NewE), Lifetime: di.Singleton},
+	{Constructor: func() F { return nil }, Lifetime: di.Singleton},
+}
+
+var HttpDefs = []*di.HttpDef{
+	{Handler: Handler, Pattern: "/"},
+}
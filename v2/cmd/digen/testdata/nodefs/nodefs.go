@@ -0,0 +1,4 @@
+package nodefs
+
+// Name is not a definition
+var Name = "nodefs"
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"strings"
+
+	"github.com/clavoie/di/v2"
+	"github.com/clavoie/di/v2/internal/static"
+)
+
+// lifetimeNames are the names of the Lifetime constants
+var lifetimeNames = map[string]bool{
+	di.Singleton.String():      true,
+	di.PerDependency.String():  true,
+	di.PerHttpRequest.String(): true,
+	di.PerResolve.String():     true,
+}
+
+// linter collects the findings for a package
+type linter struct {
+	findings []*static.ErrAt
+	pkg      *static.Package
+	seen     map[string]bool
+}
+
+// lint returns the findings for the definitions of pkg, sorted by position
+func lint(pkg *static.Package) []*static.ErrAt {
+	l := &linter{
+		findings: make([]*static.ErrAt, 0),
+		pkg:      pkg,
+		seen:     make(map[string]bool),
+	}
+
+	inVar := make(map[*static.Def]bool)
+	for _, defVar := range pkg.DefVars {
+		_, errs := static.NewGraph(pkg, defVar.Defs)
+		l.add(errs...)
+
+		for _, def := range defVar.Defs {
+			inVar[def] = true
+		}
+	}
+
+	for _, def := range pkg.Defs {
+		if inVar[def] == false {
+			_, errs := static.NewGraph(pkg, []*static.Def{def})
+			l.add(errs...)
+		}
+
+		l.lintLifetime(def)
+	}
+
+	// the definitions of a package are usually added to the same
+	// resolver, so they are checked together
+	graph, _ := static.NewGraph(pkg, pkg.Defs)
+	for _, node := range graph.Nodes {
+		if _, isStruct := node.Type.Underlying().(*types.Struct); isStruct && static.IsResultObject(node.Type) == false {
+			l.addf(node.Def.Lit, "dilint: the constructor of %v returns a struct, each dependent receives a copy of it, return an interface or a pointer instead", static.Name(node.Type))
+		}
+
+		if path := graph.CaptivePath(node); path != nil {
+			l.addf(node.Def.Lit, "%v", static.CaptiveErr(path))
+		}
+	}
+
+	if cycle := graph.Cycle(); cycle != nil {
+		path := make([]string, len(cycle))
+		for index, node := range cycle {
+			path[index] = static.Name(node.Type)
+		}
+
+		l.addf(cycle[0].Def.Lit, "di: circular dependency detected: %v", strings.Join(path, "->"))
+	}
+
+	l.lintHandlers(graph)
+	static.SortErrs(l.findings)
+	return l.findings
+}
+
+// add adds errs to the findings, once each
+func (l *linter) add(errs ...*static.ErrAt) {
+	for _, err := range errs {
+		key := err.Error()
+		if l.seen[key] {
+			continue
+		}
+
+		l.seen[key] = true
+		l.findings = append(l.findings, err)
+	}
+}
+
+// addf adds a finding at the position of node
+func (l *linter) addf(node ast.Node, format string, args ...interface{}) {
+	l.add(&static.ErrAt{
+		Err:      fmt.Errorf(format, args...),
+		Position: l.pkg.Position(node.Pos()),
+	})
+}
+
+// lintLifetime reports a Lifetime which is a known value, but is not one of
+// the Lifetime constants. Unknown values are reported by NewGraph
+func (l *linter) lintLifetime(def *static.Def) {
+	if def.LifetimeExpr == nil || def.HasLifetime == false || lifetimeNames[def.Lifetime.String()] == false {
+		return
+	}
+
+	var ident *ast.Ident
+	switch expr := def.LifetimeExpr.(type) {
+	case *ast.Ident:
+		ident = expr
+	case *ast.SelectorExpr:
+		ident = expr.Sel
+	}
+
+	if ident != nil {
+		lifetime, isConst := l.pkg.Info.Uses[ident].(*types.Const)
+		if isConst && lifetime.Pkg() != nil && lifetime.Pkg().Path() == static.DiPath && lifetimeNames[lifetime.Name()] {
+			return
+		}
+	}
+
+	l.addf(def.LifetimeExpr, "dilint: the lifetime %v is not one of the Lifetime constants, use di.%v", types.ExprString(def.LifetimeExpr), def.Lifetime)
+}
+
+// lintHandlers reports the parameters of http handlers which no definition
+// of the package provides
+func (l *linter) lintHandlers(graph *static.Graph) {
+	provided := l.provided(graph)
+
+	for _, httpDef := range l.pkg.HttpDefs {
+		if httpDef.Signature == nil {
+			continue
+		}
+
+		deps, unsupported := static.NewHandlerDeps(httpDef.Signature)
+		if unsupported != "" {
+			continue
+		}
+
+		for _, dep := range graph.Missing(deps) {
+			if provided[dep.Key] == false {
+				l.addf(httpDef.Lit, "dilint: no definition provides %v, a parameter of the handler of %q", static.Name(dep.Type), httpDef.Pattern)
+			}
+		}
+	}
+}
+
+// provided returns the keys of the types which may be provided by the
+// definitions which are not nodes of the graph, such as an annotated
+// constructor, or by the fields of a result object
+func (l *linter) provided(graph *static.Graph) map[string]bool {
+	provided := make(map[string]bool)
+	provide := func(t types.Type) {
+		provided[static.Key(t)] = true
+		provided[static.Key(types.NewSlice(t))] = true
+		provided[static.Key(types.NewMap(types.Typ[types.String], t))] = true
+	}
+
+	for _, node := range graph.Nodes {
+		if st, isStruct := node.Type.Underlying().(*types.Struct); isStruct && static.IsResultObject(node.Type) {
+			for index := 0; index < st.NumFields(); index += 1 {
+				provide(st.Field(index).Type())
+			}
+		}
+	}
+
+	for _, def := range graph.Skipped {
+		if def.Constructor == nil {
+			continue
+		}
+
+		ast.Inspect(def.Constructor, func(n ast.Node) bool {
+			expr, isExpr := n.(ast.Expr)
+			if isExpr == false {
+				return true
+			}
+
+			sig, isSig := l.pkg.Info.TypeOf(expr).(*types.Signature)
+			if isSig && sig.Results().Len() > 0 {
+				provide(sig.Results().At(0).Type())
+			}
+
+			return true
+		})
+	}
+
+	return provided
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	t.Run("Run", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		if run(buf, []string{"testdata/..."}) == false {
+			t.Fatal("expecting findings")
+		}
+
+		file := filepath.Join("testdata", "lint", "lint.go")
+		expected := []string{
+			file + ":36:2: di: Singleton lint.A captures a dependency with a shorter lifetime: lint.A->*http.Request",
+			file + ":37:2: di: circular dependency detected: lint.B->lint.C->lint.B",
+			file + ":37:32: dilint: the lifetime 1 is not one of the Lifetime constants, use di.PerDependency",
+			file + ":39:2: di: unknown lifetime: Lifetime(7)",
+			file + ":40:2: dilint: the constructor of lint.Struct returns a struct, each dependent receives a copy of it, return an interface or a pointer instead",
+			file + ":41:2: di: a dependency for lint.Struct already exists with a different lifetime: Singleton, PerDependency",
+			file + ":44:2: di: Singleton lint.Locator captures a dependency with a shorter lifetime: lint.Locator->lint.C(PerResolve)",
+			file + ":49:3: di: return value 1 cannot be an error: error",
+			file + ":54:2: dilint: no definition provides lint.Missing, a parameter of the handler of \"/\"",
+		}
+
+		findings := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(findings) != len(expected) {
+			t.Fatal("unexpected findings", buf.String())
+		}
+
+		for index, finding := range findings {
+			if finding != expected[index] {
+				t.Fatal("unexpected finding", finding, expected[index])
+			}
+		}
+	})
+	t.Run("NoPackage", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		if run(buf, []string{filepath.Join("testdata", "missing")}) == false {
+			t.Fatal("expecting error")
+		}
+	})
+	t.Run("Expand", func(t *testing.T) {
+		dirs, err := expand("./...")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(dirs) != 1 || dirs[0] != "." {
+			t.Fatal("expecting testdata to be skipped", dirs)
+		}
+
+		dirs, err = expand("testdata")
+		if err != nil || len(dirs) != 1 || dirs[0] != "testdata" {
+			t.Fatal("expecting the directory", dirs, err)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+// Command dilint reports mistakes in the dependency definitions of packages
+// without running them, so that they can be caught in CI.
+//
+// dilint finds every di.Def and di.HttpDef literal in a package, and
+// reports:
+//
+//   - definitions NewResolver would reject, such as a constructor which
+//     returns no values or an unknown Lifetime
+//   - duplicate definitions of a type in the same []*di.Def var
+//   - Lifetime values which are not one of the Lifetime constants
+//   - constructors which return a struct, so each dependent receives a copy
+//   - Singletons which capture the http request, or a value with a shorter
+//     Lifetime
+//   - circular dependencies
+//   - parameters of http handlers which no definition in the package
+//     provides
+//
+// Each finding is printed with the file:line of the definition. Annotated
+// constructors, such as di.Named(...), are not checked.
+//
+// Usage:
+//
+//	dilint [packages]
+//
+// Each package is a directory, or a directory followed by /... for the
+// directory and every directory below it. The default is the current
+// directory. The exit status is 1 if anything is reported
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/build"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/clavoie/di/v2/internal/static"
+)
+
+func main() {
+	patterns := os.Args[1:]
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	if run(os.Stdout, patterns) {
+		os.Exit(1)
+	}
+}
+
+// run lints the packages matched by patterns, writing each finding to w.
+// Returns true if anything was reported
+func run(w io.Writer, patterns []string) bool {
+	reported := false
+
+	for _, pattern := range patterns {
+		dirs, err := expand(pattern)
+		if err != nil {
+			fmt.Fprintln(w, err)
+			reported = true
+			continue
+		}
+
+		for _, dir := range dirs {
+			pkg, err := static.Load(dir, nil, nil)
+
+			var noGoErr *build.NoGoError
+			if errors.As(err, &noGoErr) && len(dirs) > 1 {
+				continue
+			}
+
+			if err != nil {
+				fmt.Fprintln(w, err)
+				reported = true
+				continue
+			}
+
+			for _, finding := range lint(pkg) {
+				fmt.Fprintln(w, finding)
+				reported = true
+			}
+		}
+	}
+
+	return reported
+}
+
+// expand returns the directories of the packages matched by pattern
+func expand(pattern string) ([]string, error) {
+	if strings.HasSuffix(filepath.ToSlash(pattern), "/...") == false {
+		return []string{pattern}, nil
+	}
+
+	root := filepath.FromSlash(strings.TrimSuffix(filepath.ToSlash(pattern), "/..."))
+	dirs := make([]string, 0)
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() == false {
+			return err
+		}
+
+		name := entry.Name()
+		if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+
+		dirs = append(dirs, path)
+		return nil
+	})
+
+	return dirs, err
+}
@@ -0,0 +1,56 @@
+package lint
+
+import (
+	"net/http"
+
+	"github.com/clavoie/di/v2"
+)
+
+type A interface{}
+type B interface{}
+type C interface{}
+type Cache interface{}
+type Locator interface{}
+type Missing interface{}
+type Named interface{}
+type Struct struct{}
+
+type Results struct {
+	di.Out
+
+	Cache Cache
+}
+
+func NewA(r *http.Request) A                                       { return nil }
+func NewB(c C) B                                                   { return nil }
+func NewC(b B) C                                                   { return nil }
+func NewLocator(c func() (C, error), r di.IResolver) Locator       { return nil }
+func NewNamed() Named                                              { return nil }
+func NewResults() Results                                          { return Results{} }
+func NewStruct() Struct                                            { return Struct{} }
+func NewError() error                                              { return nil }
+func Handler(a A, missing Missing)                                 {}
+func NamedHandler(named Named, cache Cache, w http.ResponseWriter) {}
+
+var Defs = []*di.Def{
+	{Constructor: NewA, Lifetime: di.Singleton},
+	{Constructor: NewB, Lifetime: 1},
+	{Constructor: NewC, Lifetime: di.PerResolve},
+	{Constructor: NewStruct, Lifetime: di.Lifetime(7)},
+	{Constructor: NewStruct, Lifetime: di.Singleton},
+	{Constructor: NewStruct, Lifetime: di.PerDependency},
+	{Constructor: di.Named("named", NewNamed), Lifetime: di.Singleton},
+	{Constructor: NewResults, Lifetime: di.Singleton},
+	{Constructor: NewLocator, Lifetime: di.Singleton},
+}
+
+func defs() []*di.Def {
+	return []*di.Def{
+		{Constructor: NewError, Lifetime: di.PerDependency},
+	}
+}
+
+var HttpDefs = []*di.HttpDef{
+	{Handler: Handler, Pattern: "/"},
+	{Handler: NamedHandler, Pattern: "/named"},
+}
@@ -0,0 +1,91 @@
+package di
+
+import (
+	"net/http"
+	"testing"
+)
+
+type concreteConfig struct {
+	Port int
+}
+
+type concreteService struct {
+	Config *concreteConfig
+	Port   int
+}
+
+func TestConcrete(t *testing.T) {
+	configCount := 0
+	defs := []*Def{
+		{func() *concreteConfig {
+			configCount += 1
+			return &concreteConfig{8080}
+		}, Singleton},
+		{func(c *concreteConfig) int { return c.Port }, PerResolve},
+		{func(c *concreteConfig, port int) concreteService { return concreteService{c, port} }, PerDependency},
+	}
+
+	t.Run("Resolve", func(t *testing.T) {
+		resolver, err := resolverChildNew(defs)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var service1, service2 concreteService
+		resolveErr := resolver.Resolve(&service1)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		resolveErr = resolver.Resolve(&service2)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if service1.Port != 8080 || service1.Config != service2.Config || configCount != 1 {
+			t.Fatal(service1, service2, configCount)
+		}
+
+		var fn func()
+		resolveErr = resolver.Resolve(&fn)
+		if resolveErr == nil {
+			t.Fatal("expecting missing definition err")
+		}
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{
+			{func(int) string { return "" }, Singleton},
+			{func(string) int { return 0 }, Singleton},
+		})
+
+		if err == nil {
+			t.Fatal("expecting circular dependency err")
+		}
+	})
+	t.Run("Strict", func(t *testing.T) {
+		errFn := func(er *ErrResolve, w http.ResponseWriter, r *http.Request) { panic(er) }
+		_, err := NewResolverWithOptions(errFn, Options{Strict: true}, defs)
+
+		if err == nil {
+			t.Fatal("expecting strict mode to reject concrete types")
+		}
+
+		resolver, err := NewResolverWithOptions(errFn, Options{Strict: true}, []*Def{{NewA, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		var i int
+		resolveErr = resolver.Resolve(&i)
+		if resolveErr == nil {
+			t.Fatal("expecting strict mode to reject resolving a concrete type")
+		}
+	})
+}
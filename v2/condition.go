@@ -0,0 +1,89 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Condition decides whether a definition is added to a resolver. See When
+type Condition struct {
+	// description describes the condition in error messages and DefInfo
+	description string
+
+	// test returns true if the condition is met. profiles are the active
+	// profiles of the resolver
+	test func(profiles []string) bool
+}
+
+// Profile returns a Condition which is met if any of the names is one of
+// the active profiles of the resolver. See Options.Profiles
+func Profile(names ...string) *Condition {
+	return &Condition{
+		description: fmt.Sprintf("profile(%v)", strings.Join(names, ", ")),
+		test: func(profiles []string) bool {
+			for _, name := range names {
+				for _, profile := range profiles {
+					if name == profile {
+						return true
+					}
+				}
+			}
+
+			return false
+		},
+	}
+}
+
+// Env returns a Condition which is met if the environment variable name
+// is set to value
+func Env(name, value string) *Condition {
+	return &Condition{
+		description: fmt.Sprintf("env(%v=%v)", name, value),
+		test: func([]string) bool {
+			envValue, isSet := os.LookupEnv(name)
+			return isSet && envValue == value
+		},
+	}
+}
+
+// If returns a Condition which is met if fn returns true. description
+// describes the condition in error messages and DefInfo
+func If(description string, fn func() bool) *Condition {
+	return &Condition{
+		description: fmt.Sprintf("if(%v)", description),
+		test: func([]string) bool {
+			return fn()
+		},
+	}
+}
+
+// String returns a string describing the condition
+func (c *Condition) String() string {
+	return c.description
+}
+
+// When only adds the definition of constructor to a resolver if condition
+// is met. Conditions are evaluated once, when the definition is added by
+// NewResolver:
+//
+//	defs := []*di.Def{
+//		{di.When(di.Profile("prod"), NewSmtpMailer), di.Singleton},
+//		{di.When(di.Profile("dev", "test"), NewFakeMailer), di.Singleton},
+//		{di.When(di.Env("CACHE", "redis"), NewRedisCache), di.Singleton},
+//	}
+//
+// If a type cannot be resolved because each of its definitions was left
+// out, the conditions are listed in ErrDefMissing. The condition of each
+// definition which was added is listed in DefInfo.Condition
+func When(condition *Condition, constructor interface{}) interface{} {
+	return annotate(constructor, func(af *annotatedFn) error {
+		if condition == nil {
+			return errors.New("di: When: condition cannot be nil")
+		}
+
+		af.Condition = condition
+		return nil
+	})
+}
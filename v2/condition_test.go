@@ -0,0 +1,113 @@
+package di
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWhen(t *testing.T) {
+	errFn := func(er *ErrResolve, w http.ResponseWriter, r *http.Request) { panic(er) }
+	newA := func(a int) func() A {
+		return func() A { return &aImpl{a} }
+	}
+	defs := []*Def{
+		{When(Profile("prod"), newA(1)), Singleton},
+		{When(Profile("dev", "test"), newA(2)), Singleton},
+	}
+
+	t.Run("Profile", func(t *testing.T) {
+		resolver, err := NewResolverWithOptions(errFn, Options{Profiles: []string{"test"}}, defs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a.A() != 2 {
+			t.Fatal(a.A())
+		}
+
+		infos := resolver.Definitions()
+		if len(infos) != 1 || infos[0].Condition != "profile(dev, test)" {
+			t.Fatal(infos)
+		}
+	})
+	t.Run("Excluded", func(t *testing.T) {
+		resolver, err := NewResolver(errFn, defs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr == nil {
+			t.Fatal("expecting err resolving an excluded definition")
+		}
+
+		missingErr := resolveErr.Err.(*ErrDefMissing)
+		if len(missingErr.Excluded) != 2 || strings.Contains(missingErr.Error(), "profile(prod), profile(dev, test)") == false {
+			t.Fatal(missingErr)
+		}
+	})
+	t.Run("Env", func(t *testing.T) {
+		os.Setenv("DI_TEST_WHEN", "on")
+		defer os.Unsetenv("DI_TEST_WHEN")
+
+		resolver, err := NewResolver(errFn, []*Def{
+			{When(Env("DI_TEST_WHEN", "off"), newA(1)), Singleton},
+			{When(Env("DI_TEST_WHEN", "on"), newA(2)), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil || a.A() != 2 {
+			t.Fatal(resolveErr)
+		}
+	})
+	t.Run("If", func(t *testing.T) {
+		numCalls := 0
+		isMet := func() bool {
+			numCalls += 1
+			return true
+		}
+
+		resolver, err := NewResolver(errFn, []*Def{{When(If("always", isMet), newA(3)), Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil || a.A() != 3 {
+			t.Fatal(resolveErr)
+		}
+
+		if numCalls != 1 || resolver.Definitions()[0].Condition != "if(always)" {
+			t.Fatal(numCalls, resolver.Definitions()[0].Condition)
+		}
+	})
+	t.Run("Conflict", func(t *testing.T) {
+		_, err := NewResolverWithOptions(errFn, Options{Profiles: []string{"prod", "dev"}}, defs)
+
+		if err == nil || strings.Contains(err.Error(), "when profile(dev, test)") == false {
+			t.Fatal(err)
+		}
+	})
+	t.Run("Nil", func(t *testing.T) {
+		_, err := NewResolver(errFn, []*Def{{When(nil, newA(1)), Singleton}})
+
+		if err == nil {
+			t.Fatal("expecting err for a nil condition")
+		}
+	})
+}
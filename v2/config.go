@@ -0,0 +1,408 @@
+package di
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// durationType is typeof(time.Duration)
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// ConfigSource is a source of configuration settings. See Config
+type ConfigSource interface {
+	// Lookup returns the value of the setting key, and true if the source
+	// has a value for it
+	Lookup(key string) (string, bool, error)
+}
+
+// ErrConfig is returned when a field of a configuration struct cannot be
+// set from its sources. See Config
+//
+// Implements the error interface
+type ErrConfig struct {
+	// Err is the error encountered while setting the field
+	Err error
+
+	// Field is the name of the field of the struct
+	Field string
+
+	// Key is the key of the setting the field is set from
+	Key string
+
+	// Type is the type of the configuration struct
+	Type reflect.Type
+}
+
+// Error returns an error string describing the error encountered
+func (ec *ErrConfig) Error() string {
+	return fmt.Sprintf("di: config %v.%v (%v): %v", ec.Type, ec.Field, ec.Key, ec.Err)
+}
+
+// Config defines the struct type ptrToStruct points to, with each tagged
+// field set from the first of sources which has a value for it:
+//
+//	type ServerConfig struct {
+//		Addr    string        `config:"addr,required"`
+//		Timeout time.Duration `config:"timeout" default:"5s"`
+//		Origins []string      `config:"cors.origins"`
+//	}
+//
+//	def := &di.Def{di.Config((*ServerConfig)(nil),
+//		di.FlagSource(flag.CommandLine),
+//		di.EnvSource("APP_"),
+//		di.JSONFileSource("config.json"),
+//	), di.Singleton}
+//
+// The config tag is the key of the setting, optionally followed by
+// required. If no source has a value for a field its default tag is used,
+// and if it has no default a required field is an error. Untagged fields
+// are left unchanged. Fields may be strings, bools, numbers, durations,
+// slices of those types, or any type which can be decoded from JSON.
+//
+// Each configuration is loaded when the resolver is created, and any
+// error is returned by NewResolver as an *ErrResolve with the dependency
+// chain of a definition which depends on the configuration. The struct may
+// be defined even if the resolver is strict
+func Config(ptrToStruct interface{}, sources ...ConfigSource) interface{} {
+	configType, err := ptrElem("Config", ptrToStruct)
+	if err != nil {
+		return &annotatedFn{Err: err}
+	}
+
+	if configType.Kind() != reflect.Struct {
+		return &annotatedFn{Err: fmt.Errorf("di: Config: %v is not a struct", configType)}
+	}
+
+	fields, err := newConfigFields(configType)
+	if err != nil {
+		return &annotatedFn{Err: err}
+	}
+
+	fnType := reflect.FuncOf([]reflect.Type{}, []reflect.Type{configType, errorType}, false)
+	fn := reflect.MakeFunc(fnType, func([]reflect.Value) []reflect.Value {
+		var err error
+		value := reflect.New(configType).Elem()
+
+		for _, field := range fields {
+			err = field.Set(value, sources)
+
+			if err != nil {
+				break
+			}
+		}
+
+		return []reflect.Value{value, reflect.ValueOf(&err).Elem()}
+	})
+
+	return &annotatedFn{
+		Config: &configDef{configType},
+		Fn:     fn,
+	}
+}
+
+// configDef describes a configuration struct defined with Config
+type configDef struct {
+	// Type is the type of the configuration struct
+	Type reflect.Type
+}
+
+// configField is a tagged field of a configuration struct
+type configField struct {
+	Default    string
+	HasDefault bool
+	Index      int
+	Key        string
+	Name       string
+	Required   bool
+	StructType reflect.Type
+	Type       reflect.Type
+}
+
+// newConfigFields returns the tagged fields of the configuration struct
+// configType
+func newConfigFields(configType reflect.Type) ([]*configField, error) {
+	fields := make([]*configField, 0, configType.NumField())
+
+	for index := 0; index < configType.NumField(); index += 1 {
+		structField := configType.Field(index)
+		tag, hasTag := structField.Tag.Lookup("config")
+
+		if hasTag == false || tag == "-" {
+			continue
+		}
+
+		if structField.PkgPath != "" {
+			return nil, fmt.Errorf("di: Config: field %v of %v is not exported", structField.Name, configType)
+		}
+
+		options := strings.Split(tag, ",")
+		field := &configField{
+			Index:      index,
+			Key:        strings.TrimSpace(options[0]),
+			Name:       structField.Name,
+			StructType: configType,
+			Type:       structField.Type,
+		}
+		field.Default, field.HasDefault = structField.Tag.Lookup("default")
+
+		for _, option := range options[1:] {
+			switch strings.TrimSpace(option) {
+			case "required":
+				field.Required = true
+			default:
+				return nil, fmt.Errorf("di: Config: unknown option %q in tag of field %v of %v", option, structField.Name, configType)
+			}
+		}
+
+		if field.Key == "" {
+			return nil, fmt.Errorf("di: Config: field %v of %v has no key", structField.Name, configType)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// Set sets the field on the struct value from the first source which has
+// a value for it, or from its default
+func (cf *configField) Set(value reflect.Value, sources []ConfigSource) error {
+	for _, source := range sources {
+		setting, hasSetting, err := source.Lookup(cf.Key)
+
+		if err != nil {
+			return cf.err(err)
+		}
+
+		if hasSetting {
+			return cf.set(value, setting)
+		}
+	}
+
+	if cf.HasDefault {
+		return cf.set(value, cf.Default)
+	}
+
+	if cf.Required {
+		return cf.err(errors.New("required setting is missing"))
+	}
+
+	return nil
+}
+
+// set parses setting and sets the field on the struct value
+func (cf *configField) set(value reflect.Value, setting string) error {
+	fieldValue, err := parseSetting(setting, cf.Type)
+
+	if err != nil {
+		return cf.err(err)
+	}
+
+	value.Field(cf.Index).Set(fieldValue)
+	return nil
+}
+
+// err returns a new *ErrConfig for the field
+func (cf *configField) err(err error) *ErrConfig {
+	return &ErrConfig{
+		Err:   err,
+		Field: cf.Name,
+		Key:   cf.Key,
+		Type:  cf.StructType,
+	}
+}
+
+// parseSetting parses the string value of a setting as rtype
+func parseSetting(setting string, rtype reflect.Type) (reflect.Value, error) {
+	value := reflect.New(rtype).Elem()
+
+	if rtype == durationType {
+		duration, err := time.ParseDuration(setting)
+		value.SetInt(int64(duration))
+		return value, err
+	}
+
+	var err error
+	switch rtype.Kind() {
+	case reflect.String:
+		value.SetString(setting)
+	case reflect.Bool:
+		var b bool
+		b, err = strconv.ParseBool(setting)
+		value.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var i int64
+		i, err = strconv.ParseInt(setting, 10, rtype.Bits())
+		value.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var u uint64
+		u, err = strconv.ParseUint(setting, 10, rtype.Bits())
+		value.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		f, err = strconv.ParseFloat(setting, rtype.Bits())
+		value.SetFloat(f)
+	case reflect.Slice:
+		if strings.HasPrefix(strings.TrimSpace(setting), "[") {
+			err = json.Unmarshal([]byte(setting), value.Addr().Interface())
+			break
+		}
+
+		parts := strings.Split(setting, ",")
+		value = reflect.MakeSlice(rtype, 0, len(parts))
+		for _, part := range parts {
+			var elem reflect.Value
+			elem, err = parseSetting(strings.TrimSpace(part), rtype.Elem())
+
+			if err != nil {
+				break
+			}
+
+			value = reflect.Append(value, elem)
+		}
+	default:
+		err = json.Unmarshal([]byte(setting), value.Addr().Interface())
+	}
+
+	return value, err
+}
+
+// EnvSource returns a ConfigSource which reads settings from environment
+// variables. The name of the variable is the key of the setting in upper
+// case, with dots and dashes replaced by underscores, following prefix:
+//
+//	di.EnvSource("APP_") // cors.origins => APP_CORS_ORIGINS
+func EnvSource(prefix string) ConfigSource {
+	return envSource(prefix)
+}
+
+// envSource is the ConfigSource returned by EnvSource
+type envSource string
+
+func (es envSource) Lookup(key string) (string, bool, error) {
+	name := strings.NewReplacer(".", "_", "-", "_").Replace(strings.ToUpper(key))
+	value, isSet := os.LookupEnv(string(es) + name)
+	return value, isSet, nil
+}
+
+// FlagSource returns a ConfigSource which reads settings from the flags
+// of flags with the same name as the key of the setting. Only flags which
+// were set on the command line have a value, the default of a flag is not
+// used. flags must be parsed before the resolver is created
+func FlagSource(flags *flag.FlagSet) ConfigSource {
+	return &flagSource{flags}
+}
+
+// flagSource is the ConfigSource returned by FlagSource
+type flagSource struct {
+	flags *flag.FlagSet
+}
+
+func (fs *flagSource) Lookup(key string) (string, bool, error) {
+	value, isSet := "", false
+
+	fs.flags.Visit(func(f *flag.Flag) {
+		if f.Name == key {
+			value, isSet = f.Value.String(), true
+		}
+	})
+
+	return value, isSet, nil
+}
+
+// JSONFileSource returns a ConfigSource which reads settings from the
+// JSON object in the file at path. A key containing dots refers to a
+// nested object:
+//
+//	{"cors": {"origins": ["a.com", "b.com"]}} // cors.origins
+//
+// The file is read once, the first time a setting is looked up
+func JSONFileSource(path string) ConfigSource {
+	return &jsonFileSource{path: path}
+}
+
+// jsonFileSource is the ConfigSource returned by JSONFileSource
+type jsonFileSource struct {
+	err      error
+	once     sync.Once
+	path     string
+	settings map[string]interface{}
+}
+
+func (jfs *jsonFileSource) Lookup(key string) (string, bool, error) {
+	jfs.once.Do(jfs.load)
+	if jfs.err != nil {
+		return "", false, jfs.err
+	}
+
+	var setting interface{} = jfs.settings
+	for _, part := range strings.Split(key, ".") {
+		object, isObject := setting.(map[string]interface{})
+		if isObject == false {
+			return "", false, nil
+		}
+
+		setting, isObject = object[part]
+		if isObject == false {
+			return "", false, nil
+		}
+	}
+
+	switch typedSetting := setting.(type) {
+	case nil:
+		return "", false, nil
+	case string:
+		return typedSetting, true, nil
+	case json.Number:
+		return typedSetting.String(), true, nil
+	case bool:
+		return strconv.FormatBool(typedSetting), true, nil
+	}
+
+	bytes, err := json.Marshal(setting)
+	return string(bytes), err == nil, err
+}
+
+// load reads and decodes the file of the source
+func (jfs *jsonFileSource) load() {
+	file, err := os.Open(jfs.path)
+	if err != nil {
+		jfs.err = err
+		return
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.UseNumber()
+	jfs.err = decoder.Decode(&jfs.settings)
+}
+
+// loadConfigs loads every configuration defined in the resolver, including
+// the members of groups and decorated configurations, caching Singleton
+// configurations. An error is returned for the first configuration which
+// cannot be loaded, in the order of allNodes, with the dependency chain of
+// a definition which depends on it. See Config
+func (c *resolverParent) loadConfigs() error {
+	resolver := newResolverChild(c)
+	for _, node := range allNodes(c.allDeps) {
+		if node.Annotated == nil || node.Annotated.Config == nil {
+			continue
+		}
+
+		_, err := resolver.resolveNode(dependentChain(c.allDeps, node), nil, node)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
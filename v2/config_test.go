@@ -0,0 +1,175 @@
+package di
+
+import (
+	"flag"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testConfig struct {
+	Addr    string        `config:"addr,required"`
+	Debug   bool          `config:"debug"`
+	Origins []string      `config:"cors.origins"`
+	Port    int           `config:"port" default:"8080"`
+	Ratio   float64       `config:"ratio"`
+	Timeout time.Duration `config:"timeout" default:"5s"`
+	Ignored string
+}
+
+type configUser struct {
+	config testConfig
+}
+
+func TestConfig(t *testing.T) {
+	errFn := func(er *ErrResolve, w http.ResponseWriter, r *http.Request) { panic(er) }
+	newConfigUser := func(config testConfig) *configUser { return &configUser{config} }
+
+	jsonPath := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(jsonPath, []byte(`{"addr": "json", "debug": true, "ratio": 0.5, "cors": {"origins": ["a", "b"]}}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("addr", "default", "")
+	flags.Int("port", 1, "")
+	err = flags.Parse([]string{"-port", "9090"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Sources", func(t *testing.T) {
+		os.Setenv("DI_TEST_TIMEOUT", "1m")
+		os.Setenv("DI_TEST_PORT", "7070")
+		defer os.Unsetenv("DI_TEST_TIMEOUT")
+		defer os.Unsetenv("DI_TEST_PORT")
+
+		resolver, err := NewResolverWithOptions(errFn, Options{Strict: true}, []*Def{
+			{Config((*testConfig)(nil), FlagSource(flags), EnvSource("DI_TEST_"), JSONFileSource(jsonPath)), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var config testConfig
+		resolveErr := resolver.Invoke(func(c testConfig) { config = c })
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		expected := testConfig{
+			Addr:    "json",
+			Debug:   true,
+			Origins: []string{"a", "b"},
+			Port:    9090,
+			Ratio:   0.5,
+			Timeout: time.Minute,
+		}
+
+		if reflect.DeepEqual(config, expected) == false {
+			t.Fatal(config)
+		}
+
+		if info := resolver.Definitions()[0]; info.Config == false || info.Constructor != "" {
+			t.Fatal(info)
+		}
+	})
+	t.Run("Defaults", func(t *testing.T) {
+		os.Setenv("DI_TEST_ADDR", "env")
+		os.Setenv("DI_TEST_CORS_ORIGINS", "c, d")
+		defer os.Unsetenv("DI_TEST_ADDR")
+		defer os.Unsetenv("DI_TEST_CORS_ORIGINS")
+
+		resolver, err := NewResolver(errFn, []*Def{{Config((*testConfig)(nil), EnvSource("DI_TEST_")), PerResolve}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var config testConfig
+		resolveErr := resolver.Resolve(&config)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if config.Addr != "env" || config.Port != 8080 || config.Timeout != 5*time.Second || len(config.Origins) != 2 || config.Origins[1] != "d" {
+			t.Fatal(config)
+		}
+	})
+	t.Run("Required", func(t *testing.T) {
+		_, err := NewResolver(errFn, []*Def{
+			{Config((*testConfig)(nil), EnvSource("DI_TEST_")), Singleton},
+			{newConfigUser, Singleton},
+		})
+
+		resolveErr, isResolveErr := err.(*ErrResolve)
+		if isResolveErr == false {
+			t.Fatal(err)
+		}
+
+		configErr, isConfigErr := resolveErr.Err.(*ErrConfig)
+		if isConfigErr == false || configErr.Field != "Addr" || configErr.Key != "addr" {
+			t.Fatal(resolveErr)
+		}
+
+		if len(resolveErr.DependencyChain) != 1 || resolveErr.DependencyChain[0] != reflect.TypeOf(&configUser{}) {
+			t.Fatal(resolveErr.DependencyChain)
+		}
+	})
+	t.Run("Required_Members", func(t *testing.T) {
+		config := Config((*testConfig)(nil), EnvSource("DI_TEST_"))
+		tests := []struct {
+			defs  []*Def
+			chain []reflect.Type
+		}{
+			{
+				[]*Def{{Group(config), Singleton}, {func([]testConfig) *configUser { return nil }, Singleton}},
+				[]reflect.Type{reflect.TypeOf(&configUser{}), reflect.TypeOf([]testConfig{})},
+			},
+			{
+				[]*Def{{config, Singleton}, {Decorate(func(c testConfig) testConfig { return c }), Singleton}, {newConfigUser, Singleton}},
+				[]reflect.Type{reflect.TypeOf(&configUser{}), reflect.TypeOf(testConfig{})},
+			},
+		}
+
+		for index, test := range tests {
+			_, err := NewResolver(errFn, test.defs)
+
+			resolveErr, isResolveErr := err.(*ErrResolve)
+			if isResolveErr == false {
+				t.Fatal(index, err)
+			}
+
+			if _, isConfigErr := resolveErr.Err.(*ErrConfig); isConfigErr == false || reflect.DeepEqual(resolveErr.DependencyChain, test.chain) == false {
+				t.Fatal(index, resolveErr, resolveErr.DependencyChain)
+			}
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		os.Setenv("DI_TEST_PORT", "port")
+		defer os.Unsetenv("DI_TEST_PORT")
+
+		type badTag struct {
+			A string `config:"a,unknown"`
+		}
+
+		constructors := []interface{}{
+			Config((*testConfig)(nil), EnvSource("DI_TEST_"), JSONFileSource(jsonPath)),
+			Config((*testConfig)(nil), JSONFileSource(filepath.Join(t.TempDir(), "missing.json"))),
+			Config((*badTag)(nil)),
+			Config((*int)(nil)),
+		}
+
+		for index, constructor := range constructors {
+			_, err := NewResolver(errFn, []*Def{{constructor, Singleton}})
+
+			if err == nil {
+				t.Fatal("expecting invalid config err", index)
+			}
+		}
+	})
+}
@@ -0,0 +1,293 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// maxCycles is the number of circular dependencies listed by an
+// *ErrCycle. The number of circular dependencies grows exponentially with
+// the number of definitions which depend on each other
+const maxCycles = 100
+
+// ErrCycle is returned by NewResolver when definitions depend on
+// each other in a circle. It is also returned while resolving if a
+// provider resolves a Singleton, PerHttpRequest or PerResolve definition
+// which is still being constructed.
+//
+// Implements the error interface
+type ErrCycle struct {
+	// Cycles contains a path for each circular dependency, in a stable
+	// order. Each path starts and ends with the same definition, and passes
+	// through any other definition at most once. At most 100 paths are
+	// listed, see Truncated
+	Cycles [][]*CycleDef
+
+	// Truncated is true if there are more circular dependencies than are
+	// listed in Cycles
+	Truncated bool
+}
+
+// CycleDef describes a definition in the path of a circular dependency
+type CycleDef struct {
+	// Constructor is the name of the constructor func of the definition.
+	// Empty for a group
+	Constructor string
+
+	// File is the file the constructor is declared in, if known
+	File string
+
+	// Line is the line the constructor is declared on, if known
+	Line int
+
+	// Type is the type of the value the definition resolves to
+	Type reflect.Type
+
+	// TypeName is the name of the type, followed by the name or map
+	// key of the definition if it has one
+	TypeName string
+}
+
+// newCycleDef returns a new *CycleDef describing node
+func newCycleDef(node *depNode) *CycleDef {
+	def := &CycleDef{
+		Type:     node.Type,
+		TypeName: node.TypeName,
+	}
+
+	var fn reflect.Value
+	if node.Annotated != nil {
+		fn = definedFn(node.Annotated)
+	}
+
+	if fn.IsValid() == false {
+		return def
+	}
+
+	def.Constructor = funcName(fn)
+	if runtimeFn := runtime.FuncForPC(fn.Pointer()); runtimeFn != nil {
+		def.File, def.Line = runtimeFn.FileLine(runtimeFn.Entry())
+	}
+
+	return def
+}
+
+// String returns the type name of the definition, followed by its
+// constructor and the file:line it is declared at
+func (cd *CycleDef) String() string {
+	switch {
+	case cd.Constructor == "":
+		return cd.TypeName
+	case cd.File == "":
+		return fmt.Sprintf("%v(%v)", cd.TypeName, cd.Constructor)
+	}
+
+	return fmt.Sprintf("%v(%v %v:%v)", cd.TypeName, cd.Constructor, cd.File, cd.Line)
+}
+
+// Error returns an error string describing each cycle found
+func (ec *ErrCycle) Error() string {
+	paths := make([]string, len(ec.Cycles))
+	for index, cycle := range ec.Cycles {
+		path := make([]string, len(cycle))
+		for defIndex, def := range cycle {
+			path[defIndex] = def.String()
+		}
+
+		paths[index] = strings.Join(path, "->")
+	}
+
+	switch {
+	case ec.Truncated:
+		return fmt.Sprintf("di: more than %v circular dependencies detected, the first %v are:\n\t%v", len(paths), len(paths), strings.Join(paths, "\n\t"))
+	case len(paths) == 1:
+		return fmt.Sprintf("di: circular dependency detected: %v", paths[0])
+	}
+
+	return fmt.Sprintf("di: %v circular dependencies detected:\n\t%v", len(paths), strings.Join(paths, "\n\t"))
+}
+
+// cycleFinder finds the strongly connected components of the graph of
+// definitions, and the cycles within each of them
+type cycleFinder struct {
+	components map[*depNode]int
+	cycles     [][]*CycleDef
+	index      map[*depNode]int
+	lowLink    map[*depNode]int
+	nodes      []*depNode
+	onStack    map[*depNode]bool
+	stack      []*depNode
+	truncated  bool
+
+	// blocked, blockedBy and path are the state of the search for the
+	// cycles which start with a node. See findCycles
+	blocked   map[*depNode]bool
+	blockedBy map[*depNode][]*depNode
+	path      []*depNode
+}
+
+// verifyCycles returns an *ErrCycle if any definitions in deps depend on
+// each other in a circle
+func verifyCycles(deps map[depKey]*depNode) error {
+	cf := &cycleFinder{
+		components: make(map[*depNode]int, len(deps)),
+		cycles:     make([][]*CycleDef, 0),
+		index:      make(map[*depNode]int, len(deps)),
+		lowLink:    make(map[*depNode]int, len(deps)),
+		nodes:      make([]*depNode, 0, len(deps)),
+		onStack:    make(map[*depNode]bool),
+		stack:      make([]*depNode, 0),
+	}
+
+	for _, node := range sortedNodes(deps) {
+		if _, hasIndex := cf.index[node]; hasIndex == false {
+			cf.connect(node)
+		}
+	}
+
+	for _, node := range cf.nodes {
+		if cf.truncated {
+			break
+		}
+
+		cf.findCycles(node)
+	}
+
+	if len(cf.cycles) > 0 {
+		return &ErrCycle{Cycles: cf.cycles, Truncated: cf.truncated}
+	}
+
+	return nil
+}
+
+// connect visits node and its children, assigning a component to each
+// node once every node of the component has been visited. See Tarjan's
+// strongly connected components algorithm
+func (cf *cycleFinder) connect(node *depNode) {
+	cf.index[node] = len(cf.nodes)
+	cf.lowLink[node] = len(cf.nodes)
+	cf.nodes = append(cf.nodes, node)
+	cf.stack = append(cf.stack, node)
+	cf.onStack[node] = true
+
+	for _, child := range node.Children() {
+		if _, hasIndex := cf.index[child]; hasIndex == false {
+			cf.connect(child)
+			if cf.lowLink[child] < cf.lowLink[node] {
+				cf.lowLink[node] = cf.lowLink[child]
+			}
+		} else if cf.onStack[child] && cf.index[child] < cf.lowLink[node] {
+			cf.lowLink[node] = cf.index[child]
+		}
+	}
+
+	if cf.lowLink[node] != cf.index[node] {
+		return
+	}
+
+	for {
+		member := cf.stack[len(cf.stack)-1]
+		cf.stack = cf.stack[:len(cf.stack)-1]
+		cf.onStack[member] = false
+		cf.components[member] = cf.index[node]
+
+		if member == node {
+			return
+		}
+	}
+}
+
+// findCycles records each cycle which starts and ends with start, and
+// otherwise only passes through the nodes of its component which were
+// visited after it. Each cycle is therefore recorded once, from the first
+// of its nodes to be visited. See Johnson's elementary circuits algorithm
+func (cf *cycleFinder) findCycles(start *depNode) {
+	cf.blocked = make(map[*depNode]bool)
+	cf.blockedBy = make(map[*depNode][]*depNode)
+	cf.path = make([]*depNode, 0)
+
+	cf.circuit(start, start)
+}
+
+// circuit records each cycle from start which continues through node and
+// the nodes not yet on the path. Returns true if a cycle was found
+func (cf *cycleFinder) circuit(start, node *depNode) bool {
+	isFound := false
+	cf.path = append(cf.path, node)
+	cf.blocked[node] = true
+
+	children := cf.children(start, node)
+	for _, child := range children {
+		if cf.truncated {
+			return true
+		}
+
+		switch {
+		case child == start:
+			cf.recordCycle()
+			isFound = true
+		case cf.blocked[child] == false:
+			isFound = cf.circuit(start, child) || isFound
+		}
+	}
+
+	if isFound {
+		cf.unblock(node)
+	} else {
+		for _, child := range children {
+			if containsNode(cf.blockedBy[child], node) == false {
+				cf.blockedBy[child] = append(cf.blockedBy[child], node)
+			}
+		}
+	}
+
+	cf.path = cf.path[:len(cf.path)-1]
+	return isFound
+}
+
+// children returns the children of node which may be part of a cycle
+// starting with start. See findCycles
+func (cf *cycleFinder) children(start, node *depNode) []*depNode {
+	children := make([]*depNode, 0)
+
+	for _, child := range node.Children() {
+		if cf.components[child] == cf.components[start] && cf.index[child] >= cf.index[start] {
+			children = append(children, child)
+		}
+	}
+
+	return children
+}
+
+// unblock allows node, and the nodes which were blocked by node, to be
+// added to the path again
+func (cf *cycleFinder) unblock(node *depNode) {
+	cf.blocked[node] = false
+	blockedBy := cf.blockedBy[node]
+	delete(cf.blockedBy, node)
+
+	for _, blockedNode := range blockedBy {
+		if cf.blocked[blockedNode] {
+			cf.unblock(blockedNode)
+		}
+	}
+}
+
+// recordCycle records the current path as a cycle, back to the start of
+// the path. No more cycles are recorded once maxCycles are
+func (cf *cycleFinder) recordCycle() {
+	if len(cf.cycles) == maxCycles {
+		cf.truncated = true
+		return
+	}
+
+	cycle := make([]*CycleDef, len(cf.path)+1)
+	for index, node := range cf.path {
+		cycle[index] = newCycleDef(node)
+	}
+
+	cycle[len(cf.path)] = cycle[0]
+	cf.cycles = append(cf.cycles, cycle)
+}
@@ -0,0 +1,168 @@
+package di
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCycle(t *testing.T) {
+	t.Run("Every", func(t *testing.T) {
+		defs := []*Def{
+			{func(int) string { return "" }, Singleton},
+			{func(string) int { return 0 }, Singleton},
+			{func(bool) float64 { return 0 }, PerDependency},
+			{func(float64) bool { return false }, PerDependency},
+			{func(int) uint { return 0 }, PerDependency},
+		}
+
+		_, err := resolverChildNew(defs)
+		var cycleErr *ErrCycle
+		if errors.As(err, &cycleErr) == false {
+			t.Fatal("expecting cycle err", err)
+		}
+
+		if len(cycleErr.Cycles) != 2 {
+			t.Fatal("expecting a cycle for each component", err)
+		}
+
+		expected := [][]string{{"bool", "float64", "bool"}, {"int", "string", "int"}}
+		for index, cycle := range cycleErr.Cycles {
+			if len(cycle) != len(expected[index]) {
+				t.Fatal("unexpected cycle", index, err)
+			}
+
+			for defIndex, def := range cycle {
+				if def.TypeName != expected[index][defIndex] {
+					t.Fatal("unexpected cycle", index, err)
+				}
+
+				if strings.HasSuffix(def.File, "cycle_test.go") == false || def.Line == 0 || strings.Contains(def.Constructor, "TestCycle") == false {
+					t.Fatal("expecting the location of the constructor", def)
+				}
+
+				if strings.Contains(err.Error(), def.String()) == false {
+					t.Fatal("expecting the location in the error", err)
+				}
+			}
+		}
+
+		if strings.HasPrefix(err.Error(), "di: 2 circular dependencies detected:\n\t") == false {
+			t.Fatal("unexpected error", err)
+		}
+
+		for count := 0; count < 20; count += 1 {
+			_, otherErr := resolverChildNew(defs)
+			if otherErr == nil || otherErr.Error() != err.Error() {
+				t.Fatal("expecting the same error", otherErr, err)
+			}
+		}
+	})
+	t.Run("SharedNode", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{
+			{func(string, float64) int { return 0 }, Singleton},
+			{func(int) string { return "" }, Singleton},
+			{func(int) float64 { return 0 }, Singleton},
+		})
+
+		var cycleErr *ErrCycle
+		if errors.As(err, &cycleErr) == false || len(cycleErr.Cycles) != 2 || cycleErr.Truncated {
+			t.Fatal("expecting each cycle through the shared node", err)
+		}
+
+		expected := [][]string{{"float64", "int", "float64"}, {"int", "string", "int"}}
+		for index, cycle := range cycleErr.Cycles {
+			if len(cycle) != 3 || cycle[0].TypeName != expected[index][0] || cycle[1].TypeName != expected[index][1] || cycle[2].TypeName != expected[index][2] {
+				t.Fatal("unexpected cycle", index, err)
+			}
+		}
+	})
+	t.Run("Connected", func(t *testing.T) {
+		types := []reflect.Type{
+			reflect.TypeOf(int8(0)), reflect.TypeOf(int16(0)), reflect.TypeOf(int32(0)), reflect.TypeOf(int64(0)),
+			reflect.TypeOf(uint8(0)), reflect.TypeOf(uint16(0)), reflect.TypeOf(uint32(0)), reflect.TypeOf(uint64(0)),
+			reflect.TypeOf(float32(0)), reflect.TypeOf(float64(0)), reflect.TypeOf(""), reflect.TypeOf(false),
+		}
+
+		defs := make([]*Def, len(types))
+		for index, outType := range types {
+			ins := make([]reflect.Type, 0, len(types)-1)
+			for inIndex, inType := range types {
+				if inIndex != index {
+					ins = append(ins, inType)
+				}
+			}
+
+			fnType := reflect.FuncOf(ins, []reflect.Type{outType}, false)
+			defs[index] = &Def{reflect.MakeFunc(fnType, func([]reflect.Value) []reflect.Value {
+				return []reflect.Value{reflect.Zero(outType)}
+			}).Interface(), PerDependency}
+		}
+
+		_, err := resolverChildNew(defs)
+		var cycleErr *ErrCycle
+		if errors.As(err, &cycleErr) == false {
+			t.Fatal("expecting cycle err", err)
+		}
+
+		if len(cycleErr.Cycles) != maxCycles || cycleErr.Truncated == false {
+			t.Fatal("expecting the cycles to be truncated", len(cycleErr.Cycles))
+		}
+
+		if strings.HasPrefix(err.Error(), "di: more than 100 circular dependencies detected") == false {
+			t.Fatal("unexpected error", err.Error()[:100])
+		}
+	})
+	t.Run("Derived", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{
+			{As(func(E) C { return nil }, (*D)(nil)), Singleton},
+			{func(D) E { return nil }, Singleton},
+			{Factory((*aFactory)(nil), func(a int, b B) (A, error) { return nil, nil }), Singleton},
+			{func(aFactory) B { return nil }, Singleton},
+		})
+
+		var cycleErr *ErrCycle
+		if errors.As(err, &cycleErr) == false || len(cycleErr.Cycles) != 2 {
+			t.Fatal("expecting a cycle through the alias and the factory", err)
+		}
+
+		for _, cycle := range cycleErr.Cycles {
+			for _, def := range cycle {
+				if strings.HasSuffix(def.File, "cycle_test.go") == false || strings.Contains(def.Constructor, "TestCycle") == false {
+					t.Fatal("expecting the location of the defined func", def)
+				}
+			}
+		}
+	})
+	t.Run("Single", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{
+			{func(int) string { return "" }, Singleton},
+			{func(string) int { return 0 }, Singleton},
+		})
+
+		if err == nil || strings.HasPrefix(err.Error(), "di: circular dependency detected: int(") == false {
+			t.Fatal("unexpected error", err)
+		}
+	})
+	t.Run("Group", func(t *testing.T) {
+		newPlugin := func(Plugins) Plugin { return &pluginImpl{1} }
+		newPlugins := func(ps []Plugin) Plugins { return &pluginsImpl{ps} }
+
+		_, err := resolverChildNew([]*Def{
+			{Group(newPlugin), PerDependency},
+			{newPlugins, PerDependency},
+		})
+
+		var cycleErr *ErrCycle
+		if errors.As(err, &cycleErr) == false || len(cycleErr.Cycles) != 1 {
+			t.Fatal("expecting a cycle through the group", err)
+		}
+
+		for _, def := range cycleErr.Cycles[0] {
+			if def.TypeName == "[]di.Plugin" && def.Constructor != "" {
+				t.Fatal("expecting no constructor for the group", def)
+			}
+		}
+	})
+}
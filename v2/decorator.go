@@ -0,0 +1,46 @@
+package di
+
+import "fmt"
+
+// Decorate defines fn as a decorator of an existing definition. fn must
+// return the same type as the definition it decorates, and have a parameter
+// of that type. The parameter receives the value of the definition being
+// decorated, and every other parameter is injected as usual:
+//
+//	func NewMetricsStore(inner Store, m Metrics) Store { ... }
+//
+//	defs := []*di.Def{
+//		{NewStore, di.Singleton},
+//		{di.Decorate(NewMetricsStore), di.Singleton},
+//		{di.Decorate(NewRetryStore), di.Singleton},
+//	}
+//
+// Decorators are applied in the order they are defined, each receiving
+// the value returned by the previous one, once every other definition has
+// been added to the resolver. The Lifetime of the Def is not used, the
+// decorated value is cached according to the Lifetime of the original
+// definition. Decorate may be combined with Named to decorate a named
+// definition. Like any other definition, a decorator defined more than once
+// with the same func and annotations is only applied once
+func Decorate(fn interface{}) interface{} {
+	return annotate(fn, func(af *annotatedFn) error {
+		af.Decorator = true
+		return nil
+	})
+}
+
+// decoratedParam returns the index of the parameter of a decorator which
+// receives the value being decorated. An error is returned if there is no
+// such parameter
+func decoratedParam(af *annotatedFn) (int, error) {
+	fnType := af.Fn.Type()
+	outType := fnType.Out(0)
+
+	for index := 0; index < fnType.NumIn(); index += 1 {
+		if fnType.In(index) == outType {
+			return index, nil
+		}
+	}
+
+	return 0, fmt.Errorf("di: a decorator of %v must have a parameter of type %v: %v", outType, outType, af)
+}
@@ -0,0 +1,195 @@
+package di
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type decoratedA struct {
+	inner A
+	add   int
+}
+
+func (da *decoratedA) A() int { return da.inner.A() + da.add }
+
+func TestDecorate(t *testing.T) {
+	newA := func() A { return &aImpl{1} }
+	addTen := func(inner A) A { return &decoratedA{inner, 10} }
+	double := func(b B, inner A) A { return &decoratedA{inner, inner.A()} }
+	newB := func() B { return &bImpl{} }
+
+	t.Run("Stacked", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Decorate(addTen), PerDependency},
+			{newA, Singleton},
+			{Decorate(double), PerDependency},
+			{newB, PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a1, a2 A
+		resolveErr := resolver.Resolve(&a1)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		resolveErr = resolver.Resolve(&a2)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a1.A() != 22 {
+			t.Fatal(a1.A())
+		}
+
+		if a1 != a2 {
+			t.Fatal("expecting the decorated value to keep the original Singleton lifetime")
+		}
+
+		infos := resolver.Definitions()
+		if len(infos) != 2 || len(infos[0].Decorators) != 2 || strings.Contains(infos[0].Constructor, "TestDecorate") == false {
+			t.Fatal(infos[0])
+		}
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		var created int32
+		newCountedA := func() A {
+			atomic.AddInt32(&created, 1)
+			return &aImpl{1}
+		}
+
+		resolver, err := resolverChildNew([]*Def{
+			{newCountedA, Singleton},
+			{Decorate(addTen), PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan *ErrResolve, 50)
+		for index := 0; index < 50; index += 1 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- resolver.Invoke(func(A) {})
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+		for resolveErr := range errs {
+			if resolveErr != nil {
+				t.Fatal(resolveErr)
+			}
+		}
+
+		if created != 1 {
+			t.Fatal("expecting the decorated singleton to be created once", created)
+		}
+	})
+	t.Run("Named", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{newA, Singleton},
+			{Named("one", newA), PerResolve},
+			{Named("one", Decorate(addTen)), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resolveErr := resolver.Invoke(Params(func(a, one A) {
+			if a.A() != 1 || one.A() != 11 {
+				t.Fatal(a.A(), one.A())
+			}
+		}, "", "name=one"))
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+	})
+	t.Run("Joined_InOrder", func(t *testing.T) {
+		defs1 := newDefCollection()
+		err := defs1.Add(Decorate(addTen), Singleton)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defs2 := newDefCollection()
+		err = defs2.Add(Decorate(func(inner A) A { return &decoratedA{inner, inner.A()} }), Singleton)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = defs2.Add(newA, Singleton)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		deps, err := joinDefCollection(defs1, defs2).build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		node := deps[newDepKey(aType, "")]
+		if node.Decorates == nil || node.Decorates.Decorates == nil || node.Decorates.Decorates.Decorates != nil {
+			t.Fatal("expecting two decorators")
+		}
+
+		if funcName(node.Decorates.Constructor) != funcName(reflect.ValueOf(addTen)) {
+			t.Fatal("expecting decorators to be applied in order")
+		}
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		defs := []*Def{{newA, Singleton}, {Decorate(addTen), Singleton}, {Decorate(addTen), Singleton}}
+		resolver, err := NewResolver(resolverParentErr, defs, defs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a.A() != 11 || len(resolver.Definitions()[0].Decorators) != 1 {
+			t.Fatal("expecting a duplicate decorator to be applied once", a.A())
+		}
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{
+			{newA, Singleton},
+			{func(A) B { return nil }, Singleton},
+			{Decorate(double), Singleton},
+		})
+
+		if err == nil {
+			t.Fatal("expecting circular dependency err")
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		invalid := [][]*Def{
+			{{Decorate(addTen), Singleton}},
+			{{newA, Singleton}, {Decorate(NewA), Singleton}},
+			{{newA, Singleton}, {Group(Decorate(addTen)), Singleton}},
+			{{newA, Singleton}, {Named("one", Decorate(addTen)), Singleton}},
+		}
+
+		for _, defs := range invalid {
+			_, err := resolverChildNew(defs)
+
+			if err == nil {
+				t.Fatal("expecting invalid decorator err")
+			}
+		}
+	})
+}
@@ -4,11 +4,22 @@ package di
 type Def struct {
 	// Constructor is a func which instantiates the dependency
 	// Must be a func of the signature:
-	//		func Name(dependency*) (Interface, error?)
+	//		func Name(dependency*) (Type, error?)
+	// Type may be any type other than an error, unless the resolver is
+	// strict, in which case it must be an interface. See Options.Strict
 	// Examples:
 	//    func Foo1() Dependency
 	//    func Foo2(dep1 Dep1) Dependency
 	//    func Foo3(dep1, dep2 Dep1) (Dependency, error)
+	//
+	// The func may be annotated before it is added to a Def:
+	//    Named("primary", Foo1)
+	//    Group(Foo2)
+	//    Keyed("foo", Foo2)
+	//    Params(Foo3, "name=primary", "name=replica")
+	//
+	// Or be a pre-built value, see Instance:
+	//    Instance(value)
 	Constructor interface{}
 
 	// Lifetime is the caching Lifetime of the dependency once
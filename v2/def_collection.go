@@ -16,27 +16,94 @@ var errType = reflect.TypeOf((*error)(nil)).Elem()
 
 // defCollection represents a collection of dependency definitions
 type defCollection struct {
-	deps   map[reflect.Type]*depNode
-	joined []*defCollection
+	decorators []*annotatedFn
+	deps       map[depKey]*depNode
+	joined     []*defCollection
+
+	// order contains the nodes of deps, and the members of its groups, in
+	// the order they were defined, as the order of the members of a group
+	// depends on it. Nodes derived from another definition, such as the
+	// fields of a result object, are defined again along with their parent
+	// and are not included
+	order []*depNode
+
+	// excluded are the conditions of the definitions which were not added
+	// because their condition was not met, by the key of the definition.
+	// See When
+	excluded map[depKey][]string
+
+	// profiles are the active profiles. See Options.Profiles
+	profiles []string
+
+	// replaced are the definitions replaced by an override, by the key
+	// of the definition which replaced them. See Override
+	replaced map[replacedKey][]*DefInfo
+
+	// strict indicates only interface types may be defined. See
+	// Options.Strict
+	strict bool
 }
 
 // newDefCollection creates a new Defs collection
 func newDefCollection() *defCollection {
 	return &defCollection{
-		deps:   make(map[reflect.Type]*depNode),
-		joined: make([]*defCollection, 0),
+		decorators: make([]*annotatedFn, 0),
+		deps:       make(map[depKey]*depNode),
+		excluded:   make(map[depKey][]string),
+		joined:     make([]*defCollection, 0),
+		replaced:   make(map[replacedKey][]*DefInfo),
 	}
 }
 
+// replacedKey is the key of a definition which may be replaced by an
+// override. For a member of a map of definitions it is the key of the map
+// along with the map key of the member. Members of a group are never
+// replaced
+type replacedKey struct {
+	key    depKey
+	mapKey string
+}
+
 // Add adds a dependency definition to this Defs collection. See Def.Constructor
 // for the format of the constructor parameter
 func (d *defCollection) Add(constructor interface{}, lifetime Lifetime) error {
-	constructorValue := reflect.ValueOf(constructor)
-	arg1, err := d.verifyConstructor(constructorValue, lifetime)
+	annotated, err := newAnnotatedFn(constructor)
+
+	if err != nil {
+		return err
+	}
+
+	if annotated.Condition != nil && annotated.Condition.test(d.profiles) == false {
+		d.exclude(annotated)
+		return nil
+	}
+
+	return d.add(annotated, lifetime)
+}
+
+// add adds an annotated constructor to this Defs collection
+func (d *defCollection) add(annotated *annotatedFn, lifetime Lifetime) error {
+	if annotated.Alias {
+		lifetime = PerDependency
+	}
+
+	inj, err := newInjection(annotated)
+
+	if err != nil {
+		return err
+	}
+
+	key, err := d.verifyConstructor(annotated, inj, lifetime)
+
+	if err == nil && annotated.Decorator {
+		d.decorators = append(d.decorators, annotated)
+		return nil
+	}
 
 	if err != nil {
 		if err == duplicateDefErr {
-			return nil
+			// the constructor may be defined as additional types
+			return d.addAs(annotated)
 		}
 
 		return err
@@ -46,13 +113,234 @@ func (d *defCollection) Add(constructor interface{}, lifetime Lifetime) error {
 		return fmt.Errorf("di: unknown lifetime: %v", lifetime)
 	}
 
-	newNode := newDepNode(constructorValue, lifetime, d.deps)
-	d.deps[arg1] = newNode
+	if annotated.IsInstance() && lifetime != Singleton {
+		return fmt.Errorf("di: an instance of %v must have a Singleton lifetime: %v", key, lifetime)
+	}
+
+	newNode := newDepNode(annotated, inj, lifetime, d.deps)
+	existingDep, hasDep := d.existing(annotated, key)
+	replaceKey := replacedKey{key: key}
+	if annotated.Keyed {
+		replaceKey = replacedKey{key: annotated.GroupKey(key), mapKey: annotated.MapKey}
+	}
+
+	if hasDep {
+		d.replace(replaceKey, existingDep)
+	}
+
+	d.addOrder(existingDep, newNode)
+
+	if annotated.Group == false {
+		newNode.Replaced = d.replaced[replaceKey]
+	}
+
+	if annotated.Group || annotated.Keyed {
+		group := d.group(annotated.GroupKey(key))
+		if index := memberIndex(group, newNode.MapKey); annotated.Keyed && index >= 0 {
+			group.Members[index] = newNode
+			return nil
+		}
+
+		group.Members = append(group.Members, newNode)
+		return nil
+	}
+
+	d.deps[key] = newNode
+	d.addEdges(newNode)
+
+	if isResultObject(key.Type) {
+		return d.addResultFields(annotated, lifetime)
+	}
+
+	return d.addAs(annotated)
+}
+
+// decorate replaces the node defined for the type returned by decorator
+// with a node which decorates it. See Decorate
+func (d *defCollection) decorate(decorator *annotatedFn) error {
+	inj, err := newInjection(decorator)
+
+	if err != nil {
+		return err
+	}
+
+	innerIndex, err := decoratedParam(decorator)
+	if err != nil {
+		return err
+	}
+
+	key := newDepKey(decorator.Fn.Type().Out(0), decorator.Name)
+	inner, hasInner := d.deps[key]
+	if hasInner == false {
+		return fmt.Errorf("di: no definition of %v to decorate: %v", key, decorator)
+	}
+
+	innerDep := inj.Params[innerIndex].Deps[0]
+	innerDep.Key = key
+	innerDep.Node = inner
+
+	newNode := newDepNode(decorator, inj, inner.Lifetime, d.deps)
+	newNode.Decorates = inner
+	d.deps[key] = newNode
+	d.addEdges(newNode)
+
+	return nil
+}
+
+// addAs adds an Alias for each of the As types of annotated
+func (d *defCollection) addAs(annotated *annotatedFn) error {
+	aliases, err := newAsFns(annotated)
+
+	if err != nil {
+		return err
+	}
+
+	for _, alias := range aliases {
+		err = d.add(alias, PerDependency)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addResultFields adds a definition for each field of the result object
+// returned by annotated. See Out
+func (d *defCollection) addResultFields(annotated *annotatedFn, lifetime Lifetime) error {
+	fields, err := newResultFields(annotated)
+
+	if err != nil {
+		return err
+	}
+
+	for _, field := range fields {
+		field.Override = annotated.Override
+		err = d.add(field, lifetime)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addEdges adds an edge to newNode from every node in this collection
+// which depends on it
+func (d *defCollection) addEdges(newNode *depNode) {
 	for _, node := range d.deps {
 		node.AddEdge(newNode)
+
+		for _, member := range node.Members {
+			member.AddEdge(newNode)
+		}
+	}
+}
+
+// exclude records that the definition of annotated was not added because
+// its condition was not met. See When
+func (d *defCollection) exclude(annotated *annotatedFn) {
+	fnType := annotated.Fn.Type()
+	if fnType.NumOut() == 0 || annotated.Decorator {
+		return
 	}
 
-	return nil
+	key := newDepKey(fnType.Out(0), annotated.Name)
+	if annotated.Group || annotated.Keyed {
+		key = annotated.GroupKey(key)
+	}
+
+	d.excluded[key] = append(d.excluded[key], annotated.Condition.String())
+}
+
+// replace records that existingNode, defined for key, has been replaced
+// by an override. See Override
+func (d *defCollection) replace(key replacedKey, existingNode *depNode) {
+	info := newDefInfo(existingNode)
+	info.Replaced = []*DefInfo{}
+
+	d.replaced[key] = append(d.replaced[key], info)
+}
+
+// addOrder records that newNode was defined, in place of existingNode if
+// newNode replaces it. See order
+func (d *defCollection) addOrder(existingNode *depNode, newNode *depNode) {
+	isDerived := newNode.Annotated.Parent != nil
+
+	for index, node := range d.order {
+		if existingNode == nil || node != existingNode {
+			continue
+		}
+
+		if isDerived {
+			d.order = append(d.order[:index], d.order[index+1:]...)
+		} else {
+			d.order[index] = newNode
+		}
+
+		return
+	}
+
+	if isDerived == false {
+		d.order = append(d.order, newNode)
+	}
+}
+
+// existing returns the node already defined in this collection for an
+// annotated constructor, if there is one. Members of a group and
+// decorators are never considered to be already defined, but members of
+// a map are if they have the same map key
+func (d *defCollection) existing(annotated *annotatedFn, key depKey) (*depNode, bool) {
+	if annotated.Group || annotated.Decorator {
+		return nil, false
+	}
+
+	if annotated.Keyed == false {
+		existingDep, hasDep := d.deps[key]
+		return existingDep, hasDep
+	}
+
+	group, hasGroup := d.deps[annotated.GroupKey(key)]
+	if hasGroup == false {
+		return nil, false
+	}
+
+	index := memberIndex(group, annotated.MapKey)
+	if index < 0 {
+		return nil, false
+	}
+
+	return group.Members[index], true
+}
+
+// memberIndex returns the index of the member of a map of definitions
+// with the specified map key, or -1 if there is no such member
+func memberIndex(group *depNode, mapKey string) int {
+	for index, member := range group.Members {
+		if member.MapKey == mapKey {
+			return index
+		}
+	}
+
+	return -1
+}
+
+// group returns the group node with the specified key, creating it if
+// it does not exist yet
+func (d *defCollection) group(key depKey) *depNode {
+	group, hasGroup := d.deps[key]
+
+	if hasGroup {
+		return group
+	}
+
+	group = newGroupNode(key)
+	d.deps[key] = group
+	d.addEdges(group)
+
+	return group
 }
 
 // AddAll is a bulk version of Add
@@ -68,12 +356,10 @@ func (d *defCollection) AddAll(defs []*Def) error {
 	return nil
 }
 
+// all returns every definition of this collection and the collections
+// joined to it, in the order they were defined. See order
 func (d *defCollection) all() []*depNode {
-	deps := make([]*depNode, 0, len(d.deps)+len(d.joined))
-
-	for _, dep := range d.deps {
-		deps = append(deps, dep)
-	}
+	deps := append([]*depNode{}, d.order...)
 
 	for _, defs := range d.joined {
 		deps = append(deps, defs.all()...)
@@ -82,89 +368,222 @@ func (d *defCollection) all() []*depNode {
 	return deps
 }
 
-func (d *defCollection) build() (map[reflect.Type]*depNode, error) {
+// allReplaced returns the definitions replaced by an override in this
+// collection and the collections joined to it, in the order they were
+// replaced
+func (d *defCollection) allReplaced() map[replacedKey][]*DefInfo {
+	replaced := make(map[replacedKey][]*DefInfo, len(d.replaced))
+	for key, infos := range d.replaced {
+		replaced[key] = append([]*DefInfo{}, infos...)
+	}
+
+	for _, defs := range d.joined {
+		for key, infos := range defs.allReplaced() {
+			replaced[key] = append(replaced[key], infos...)
+		}
+	}
+
+	return replaced
+}
+
+// allExcluded returns the conditions of the definitions which were not
+// added to this collection, or to the collections joined to it
+func (d *defCollection) allExcluded() map[depKey][]string {
+	excluded := make(map[depKey][]string, len(d.excluded))
+	for key, conditions := range d.excluded {
+		excluded[key] = append([]string{}, conditions...)
+	}
+
+	for _, defs := range d.joined {
+		for key, conditions := range defs.allExcluded() {
+			excluded[key] = append(excluded[key], conditions...)
+		}
+	}
+
+	return excluded
+}
+
+// allDecorators returns every decorator of this collection and the
+// collections joined to it, in the order they were defined. Like a
+// duplicate definition, a decorator defined more than once is only
+// returned the first time
+func (d *defCollection) allDecorators() []*annotatedFn {
+	defined := append([]*annotatedFn{}, d.decorators...)
+	for _, defs := range d.joined {
+		defined = append(defined, defs.allDecorators()...)
+	}
+
+	decorators := make([]*annotatedFn, 0, len(defined))
+	for _, decorator := range defined {
+		if containsDecorator(decorators, decorator) == false {
+			decorators = append(decorators, decorator)
+		}
+	}
+
+	return decorators
+}
+
+// containsDecorator returns true if decorators contains the same func as
+// decorator with the same annotations
+func containsDecorator(decorators []*annotatedFn, decorator *annotatedFn) bool {
+	for _, existing := range decorators {
+		if existing.String() == decorator.String() && existing.Name == decorator.Name &&
+			reflect.DeepEqual(existing.Params, decorator.Params) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *defCollection) build() (map[depKey]*depNode, error) {
 	allDeps := d.all()
 	finalDeps := &defCollection{
-		deps: make(map[reflect.Type]*depNode, len(allDeps)),
+		deps:     make(map[depKey]*depNode, len(allDeps)),
+		replaced: d.allReplaced(),
+		strict:   d.strict,
 	}
 
 	for _, dep := range allDeps {
-		err := finalDeps.Add(dep.Constructor.Interface(), dep.Lifetime)
+		err := finalDeps.add(dep.Annotated, dep.Lifetime)
 
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	checked := make(map[*depNode]bool, len(d.deps))
-
-	for _, node := range finalDeps.deps {
-		if checked[node] {
-			continue
-		}
-
-		err := node.CheckForCycle([]*depNode{}, checked)
+	for _, decorator := range d.allDecorators() {
+		err := finalDeps.decorate(decorator)
 
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	err := verifyCycles(finalDeps.deps)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyModules(finalDeps.deps)
+	if err != nil {
+		return nil, err
+	}
+
+	err = verifyCaptives(finalDeps.deps)
+	if err != nil {
+		return nil, err
+	}
+
 	return finalDeps.deps, nil
 }
 
 // joinDefCollection combines two Defs collections together into a new Defs
 func joinDefCollection(ds ...*defCollection) *defCollection {
 	return &defCollection{
-		deps:   make(map[reflect.Type]*depNode),
-		joined: ds,
+		decorators: make([]*annotatedFn, 0),
+		deps:       make(map[depKey]*depNode),
+		excluded:   make(map[depKey][]string),
+		joined:     ds,
+		replaced:   make(map[replacedKey][]*DefInfo),
 	}
 }
 
-func (d *defCollection) verifyConstructor(constructorValue reflect.Value, lifetime Lifetime) (reflect.Type, error) {
-	var arg1 reflect.Type
+func (d *defCollection) verifyConstructor(annotated *annotatedFn, inj *injection, lifetime Lifetime) (depKey, error) {
+	var key depKey
+	constructorValue := annotated.Fn
 
 	if constructorValue.Kind() != reflect.Func {
-		return arg1, fmt.Errorf("di: constructor argument is not a function: %v", constructorValue.Kind())
+		return key, fmt.Errorf("di: constructor argument is not a function: %v", constructorValue.Kind())
 	}
 
 	constructorType := constructorValue.Type()
 	numOut := constructorType.NumOut()
 	if numOut == 0 || numOut > 2 {
-		return arg1, fmt.Errorf("di: constructor can return exactly 1 or 2 values")
+		return key, fmt.Errorf("di: constructor can return exactly 1 or 2 values")
 	}
 
-	arg1 = constructorType.Out(0)
+	arg1 := constructorType.Out(0)
+	key = newDepKey(arg1, annotated.Name)
 	if arg1.Implements(errType) {
-		return arg1, fmt.Errorf("di: return value 1 cannot be an error: %v", arg1)
+		return key, fmt.Errorf("di: return value 1 cannot be an error: %v", arg1)
 	}
 
-	if arg1.Kind() != reflect.Interface {
-		return arg1, fmt.Errorf("di: return value 1 must be an interface: %v", arg1)
+	if isResultObject(arg1) && (annotated.Name != "" || annotated.Group || annotated.Keyed || len(annotated.As) > 0) {
+		return key, fmt.Errorf("di: a result object cannot be named or grouped, tag its fields instead: %v", arg1)
 	}
 
-	existingDep, hasDep := d.deps[arg1]
-	if hasDep {
-		existing := fmt.Sprintf("%#v", existingDep.Constructor)
-		newConstructor := fmt.Sprintf("%#v", constructorValue)
-		if existing != newConstructor {
-			return arg1, fmt.Errorf("di: a dependency for %v already exists with a different constructor:  %v, %v", arg1, existing, newConstructor)
+	if d.strict && arg1.Kind() != reflect.Interface && isResultObject(arg1) == false && annotated.Config == nil {
+		return key, fmt.Errorf("di: return value 1 must be an interface: %v", arg1)
+	}
+
+	if arg1 == iresolverType || arg1 == requestType || arg1 == responseWriterType {
+		return key, fmt.Errorf("di: return value 1 is supplied by the resolver and cannot be defined: %v", arg1)
+	}
+
+	if _, isProvider := providerElem(arg1); isProvider {
+		return key, fmt.Errorf("di: return value 1 is a provider type and cannot be defined: %v", arg1)
+	}
+
+	if len(annotated.As) > 0 && (annotated.Group || annotated.Keyed) {
+		return key, fmt.Errorf("di: a group member cannot also be defined as other types: %v", key)
+	}
+
+	if annotated.Decorator && (annotated.Group || annotated.Keyed || len(annotated.As) > 0 || isResultObject(arg1)) {
+		return key, fmt.Errorf("di: a decorator of %v cannot be grouped, keyed, aliased, or return a result object", key)
+	}
+
+	if annotated.Override && (annotated.Group || annotated.Decorator) {
+		return key, fmt.Errorf("di: a group member or decorator of %v cannot be an override", key)
+	}
+
+	if annotated.Decorator {
+		if _, err := decoratedParam(annotated); err != nil {
+			return key, err
 		}
+	}
+
+	if annotated.Group && annotated.Keyed {
+		return key, fmt.Errorf("di: a definition for %v cannot be both a group member and keyed", key)
+	}
 
-		if existingDep.Lifetime != lifetime {
-			return arg1, fmt.Errorf("di: a dependency for %v already exists with a different lifetime: %v, %v", arg1, existingDep.Lifetime, lifetime)
+	if annotated.Group || annotated.Keyed {
+		groupKey := annotated.GroupKey(key)
+		if groupDep, hasGroup := d.deps[groupKey]; hasGroup && groupDep.IsGroup() == false {
+			return key, fmt.Errorf("di: a dependency for %v already exists, it cannot also be a group of definitions", groupKey)
 		}
+	}
+
+	existingDep, hasDep := d.existing(annotated, key)
+	if hasDep && existingDep.IsGroup() {
+		return key, fmt.Errorf("di: a group of definitions for %v already exists, it cannot also be a dependency", key)
+	}
 
-		return arg1, duplicateDefErr
+	if hasDep {
+		existing := existingDep.Annotated.String()
+		newConstructor := annotated.String()
+		sameConstructor := existing == newConstructor && existingDep.HasSameDeps(inj.Deps) &&
+			existingDep.Annotated.SameInstance(annotated)
+
+		switch {
+		case sameConstructor && existingDep.Lifetime == lifetime:
+			return key, duplicateDefErr
+		case annotated.Override:
+			// the existing definition is replaced by add
+		case sameConstructor == false:
+			return key, fmt.Errorf("di: a dependency for %v already exists with a different constructor:  %v, %v", existingDep.TypeName, existing, newConstructor)
+		default:
+			return key, fmt.Errorf("di: a dependency for %v already exists with a different lifetime: %v, %v", existingDep.TypeName, existingDep.Lifetime, lifetime)
+		}
 	}
 
 	if numOut == 2 {
 		arg2 := constructorType.Out(1)
 
 		if arg2.Implements(errType) == false {
-			return arg1, fmt.Errorf("di: return value 2, if provided, must be an error: %v", arg2)
+			return key, fmt.Errorf("di: return value 2, if provided, must be an error: %v", arg2)
 		}
 	}
 
-	return arg1, nil
+	return key, nil
 }
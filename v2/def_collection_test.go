@@ -1,6 +1,9 @@
 package di
 
-import "testing"
+import (
+	"net/http"
+	"testing"
+)
 
 func TestDefs(t *testing.T) {
 	t.Run("Add", func(t *testing.T) {
@@ -17,6 +20,14 @@ func TestDefs(t *testing.T) {
 				t.Fatal(err)
 			}
 		})
+		t.Run("NotIface", func(t *testing.T) {
+			defs := newDefCollection()
+			err := defs.Add(func() int { return 0 }, Singleton)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
 		t.Run("Invalid", func(t *testing.T) {
 			t.Run("NotFn", func(t *testing.T) {
 				defs := newDefCollection()
@@ -50,14 +61,36 @@ func TestDefs(t *testing.T) {
 					t.Fatal(err)
 				}
 			})
-			t.Run("Out1IsNotIface", func(t *testing.T) {
+			t.Run("Out1IsNotIface_Strict", func(t *testing.T) {
 				defs := newDefCollection()
+				defs.strict = true
 				err := defs.Add(func() int { return 0 }, Singleton)
 
 				if err == nil {
 					t.Fatal(err)
 				}
 			})
+			t.Run("Out1IsResolverType", func(t *testing.T) {
+				defs := newDefCollection()
+				err := defs.Add(func() *http.Request { return nil }, Singleton)
+
+				if err == nil {
+					t.Fatal(err)
+				}
+			})
+			t.Run("GroupAndDependency", func(t *testing.T) {
+				defs := newDefCollection()
+				err := defs.Add(Group(NewA), Singleton)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = defs.Add(func() []A { return nil }, Singleton)
+				if err == nil {
+					t.Fatal("was expecting group and dependency of the same type to fail")
+				}
+			})
 			t.Run("Out2IsNotErr", func(t *testing.T) {
 				defs := newDefCollection()
 				err := defs.Add(func() (B, int) { return nil, 4 }, Singleton)
@@ -92,6 +125,24 @@ func TestDefs(t *testing.T) {
 					t.Fatal("was expecting duplicate definition to fail")
 				}
 			})
+			t.Run("DuplicateName", func(t *testing.T) {
+				defs := newDefCollection()
+				err := defs.Add(Named("a", NewA), PerResolve)
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				err = defs.Add(Named("a", func() A { return nil }), PerResolve)
+				if err == nil {
+					t.Fatal("was expecting duplicate named definition to fail")
+				}
+
+				err = defs.Add(Named("b", func() A { return nil }), PerResolve)
+				if err != nil {
+					t.Fatal(err)
+				}
+			})
 			t.Run("DifferentLifetime", func(t *testing.T) {
 				defs := newDefCollection()
 				err := defs.Add(NewA, PerResolve)
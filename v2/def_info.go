@@ -0,0 +1,177 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// DefInfo describes a definition known to a resolver. See
+// IHttpResolver.Definitions
+type DefInfo struct {
+	// Alias is true if the definition is an alias of its only dependency.
+	// See Alias
+	Alias bool
+
+	// Condition describes the condition which was met for the definition
+	// to be added, if any. See When
+	Condition string
+
+	// Config is true if the definition is a configuration struct. See
+	// Config
+	Config bool
+
+	// Constructor is the name of the constructor func of the definition.
+	// Empty for instance, configuration and Alias definitions. For the
+	// fields of a result object and the aliases added by As this is the
+	// constructor they were derived from
+	Constructor string
+
+	// Decorators are the names of the decorators of the definition, in
+	// the order they are applied. See Decorate
+	Decorators []string
+
+	// DependsOn are the dependencies of the definition, in the order they
+	// are passed to the constructor
+	DependsOn []string
+
+	// Factory is true if the definition is a factory. The Constructor is
+	// the constructor the factory calls. See Factory
+	Factory bool
+
+	// Group is true if the definition is a member of a group. See Group
+	Group bool
+
+	// Instance is true if the definition is a pre-built value. See Instance
+	Instance bool
+
+	// Keyed is true if the definition is a member of a map under MapKey.
+	// See Keyed
+	Keyed bool
+
+	// Lifetime is the caching Lifetime of the definition. For an alias this
+	// is the Lifetime of its target
+	Lifetime Lifetime
+
+	// MapKey is the key of the definition in a map of definitions. Only
+	// valid if Keyed is true
+	MapKey string
+
+	// Module is the name of the module the definition is part of, if any.
+	// See Module
+	Module string
+
+	// Name is the name the definition is registered under, if any. See Named
+	Name string
+
+	// Replaced are the definitions replaced by this definition, in the
+	// order they were replaced. See Override
+	Replaced []*DefInfo
+
+	// Type is the type of the value the definition resolves to
+	Type reflect.Type
+}
+
+// newDefInfo returns a new *DefInfo describing node
+func newDefInfo(node *depNode) *DefInfo {
+	if node.Decorates != nil {
+		info := newDefInfo(node.Decorates)
+		info.Decorators = append(info.Decorators, funcName(node.Constructor))
+		return info
+	}
+
+	dependsOn := make([]string, len(node.DependsOn))
+	for index, dep := range node.DependsOn {
+		dependsOn[index] = dep.Key.String()
+
+		if dep.Provider != nil {
+			dependsOn[index] = fmt.Sprintf("func() (%v, error)", dep.Key)
+		}
+	}
+
+	info := &DefInfo{
+		Alias:      node.Annotated.Alias,
+		Config:     node.Annotated.Config != nil,
+		Decorators: []string{},
+		DependsOn:  dependsOn,
+		Factory:    node.Annotated.Factory.IsValid(),
+		Group:      node.Annotated.Group,
+		Instance:   node.Annotated.IsInstance(),
+		Keyed:      node.Annotated.Keyed,
+		Lifetime:   node.Lifetime,
+		MapKey:     node.MapKey,
+		Name:       node.Key.Name,
+		Replaced:   append([]*DefInfo{}, node.Replaced...),
+		Type:       node.Type,
+	}
+
+	if m := moduleOf(node); m != nil {
+		info.Module = m.Name
+	}
+
+	if condition := node.Annotated.Root().Condition; condition != nil {
+		info.Condition = condition.String()
+	}
+
+	if info.Alias {
+		if target, hasTarget := node.Edges[node.DependsOn[0].Key]; hasTarget {
+			info.Lifetime = target.Lifetime
+		}
+	}
+
+	if fn := definedFn(node.Annotated); fn.IsValid() {
+		info.Constructor = funcName(fn)
+	}
+
+	return info
+}
+
+// definedFn returns the func annotated was defined with, as opposed to the
+// func built to construct its value, or an invalid value if it was not
+// defined with a func
+func definedFn(annotated *annotatedFn) reflect.Value {
+	switch {
+	case annotated.Field != nil:
+		return definedFn(annotated.Field.Result)
+	case annotated.Alias && annotated.Parent != nil:
+		return definedFn(annotated.Parent)
+	case annotated.Factory.IsValid():
+		return annotated.Factory
+	case annotated.Alias || annotated.IsInstance() || annotated.Config != nil:
+		return reflect.Value{}
+	}
+
+	return annotated.Fn
+}
+
+// newDefInfos returns a *DefInfo for each definition in deps, sorted by
+// type and name. Members of groups and maps are returned in the order
+// they were defined
+func newDefInfos(deps map[depKey]*depNode) []*DefInfo {
+	nodes := sortedNodes(deps)
+
+	infos := make([]*DefInfo, 0, len(nodes))
+	for _, node := range nodes {
+		if node.IsGroup() == false {
+			infos = append(infos, newDefInfo(node))
+			continue
+		}
+
+		for _, member := range node.Members {
+			infos = append(infos, newDefInfo(member))
+		}
+	}
+
+	return infos
+}
+
+// funcName returns the name of the func fn
+func funcName(fn reflect.Value) string {
+	runtimeFn := runtime.FuncForPC(fn.Pointer())
+
+	if runtimeFn == nil {
+		return fn.Type().String()
+	}
+
+	return runtimeFn.Name()
+}
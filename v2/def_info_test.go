@@ -0,0 +1,85 @@
+package di
+
+import (
+	htmltemplate "html/template"
+	"reflect"
+	"strings"
+	"testing"
+	texttemplate "text/template"
+)
+
+func TestDefInfo(t *testing.T) {
+	resolver, err := resolverChildNew([]*Def{
+		{NewB, PerDependency},
+		{Named("one", NewA), Singleton},
+		{Instance(&concreteConfig{}), Singleton},
+		{Group(func() Plugin { return nil }), PerResolve},
+		{Group(func() Plugin { return nil }), PerDependency},
+		{Keyed("one", func() Plugin { return nil }), Singleton},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	infos := resolver.Definitions()
+	if len(infos) != 6 {
+		t.Fatal(len(infos))
+	}
+
+	expected := []string{"*di.concreteConfig", "[]di.Plugin", "[]di.Plugin", "di.A[one]", "di.B", "map[string]di.Plugin"}
+	for index, info := range infos {
+		typeName := info.Type.String()
+		if info.Name != "" {
+			typeName += "[" + info.Name + "]"
+		}
+
+		if info.Group {
+			typeName = reflect.SliceOf(info.Type).String()
+		}
+
+		if info.Keyed {
+			typeName = reflect.MapOf(stringType, info.Type).String()
+		}
+
+		if typeName != expected[index] {
+			t.Fatal(index, typeName, expected[index])
+		}
+	}
+
+	if infos[0].Instance == false || infos[0].Constructor != "" {
+		t.Fatal(infos[0])
+	}
+
+	if infos[1].Lifetime != PerResolve || infos[2].Lifetime != PerDependency {
+		t.Fatal("expecting group members in the order they were defined")
+	}
+
+	if strings.HasSuffix(infos[3].Constructor, "NewA") == false {
+		t.Fatal(infos[3].Constructor)
+	}
+
+	if len(infos[4].DependsOn) != 2 || infos[4].DependsOn[0] != "di.A" {
+		t.Fatal(infos[4].DependsOn)
+	}
+
+	if infos[5].MapKey != "one" {
+		t.Fatal(infos[5].MapKey)
+	}
+
+	for count := 0; count < 20; count += 1 {
+		resolver, err = resolverChildNew([]*Def{
+			{func() *texttemplate.Template { return nil }, Singleton},
+			{func() *htmltemplate.Template { return nil }, Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		infos = resolver.Definitions()
+		if infos[0].Type != reflect.TypeOf(&htmltemplate.Template{}) {
+			t.Fatal("expecting types with the same name sorted by package path", infos[0].Type)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// depKey identifies a dependency definition by its type and, optionally,
+// the name it was registered under
+type depKey struct {
+	Name string
+	Type reflect.Type
+}
+
+// newDepKey returns a new depKey for the specified type and name
+func newDepKey(rtype reflect.Type, name string) depKey {
+	return depKey{
+		Name: name,
+		Type: rtype,
+	}
+}
+
+// String returns the type name of the key, followed by the name of
+// the definition in brackets if it has one
+func (dk depKey) String() string {
+	if dk.Name == "" {
+		return dk.Type.String()
+	}
+
+	return fmt.Sprintf("%v[%v]", dk.Type, dk.Name)
+}
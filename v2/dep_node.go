@@ -3,44 +3,63 @@ package di
 import (
 	"fmt"
 	"reflect"
-	"strings"
+	"sort"
 )
 
 type depNode struct {
+	Annotated   *annotatedFn
 	Constructor reflect.Value
-	DependsOn   []reflect.Type
-	Edges       map[reflect.Type]*depNode
+	Decorates   *depNode
+	DependsOn   []*dependency
+	Edges       map[depKey]*depNode
+	Injection   *injection
+	Key         depKey
 	Lifetime    Lifetime
+	MapKey      string
+	Members     []*depNode
+	Replaced    []*DefInfo
 	ReturnsErr  bool
 	Type        reflect.Type
 	TypeName    string
 }
 
-func newDepNode(constructor reflect.Value, lifetime Lifetime, depMap map[reflect.Type]*depNode) *depNode {
+func newDepNode(annotated *annotatedFn, inj *injection, lifetime Lifetime, depMap map[depKey]*depNode) *depNode {
 	var node depNode
 
-	node.Constructor = constructor
+	node.Annotated = annotated
+	node.Constructor = annotated.Fn
+	node.Injection = inj
 	node.Lifetime = lifetime
 
-	constructorType := constructor.Type()
+	constructorType := node.Constructor.Type()
 	node.Type = constructorType.Out(0)
-	node.TypeName = node.Type.String()
+	node.Key = newDepKey(node.Type, annotated.Name)
+	node.TypeName = node.Key.String()
+
+	if annotated.Keyed {
+		node.MapKey = annotated.MapKey
+		node.TypeName = fmt.Sprintf("%v{%q}", node.Key, node.MapKey)
+	}
 
 	if constructorType.NumOut() == 2 {
 		node.ReturnsErr = true
 	}
 
-	numIn := constructorType.NumIn()
-	deps := make([]reflect.Type, numIn)
-	edges := make(map[reflect.Type]*depNode, numIn)
+	deps := inj.Deps
+	edges := make(map[depKey]*depNode, len(deps))
+
+	for _, dep := range deps {
+		if dep.Provider != nil {
+			continue
+		}
 
-	for index := range deps {
-		inType := constructorType.In(index)
-		deps[index] = inType
+		edgeNode, hasNode := depMap[dep.Key]
+		if dep.Node != nil {
+			edgeNode, hasNode = dep.Node, true
+		}
 
-		edgeNode, hasNode := depMap[inType]
 		if hasNode {
-			edges[inType] = edgeNode
+			edges[dep.Key] = edgeNode
 		}
 	}
 
@@ -50,54 +69,93 @@ func newDepNode(constructor reflect.Value, lifetime Lifetime, depMap map[reflect
 	return &node
 }
 
+// newBuiltinNode returns a node for a type which is supplied by the
+// resolver itself instead of by a definition, such as *http.Request
+func newBuiltinNode(rtype reflect.Type, lifetime Lifetime) *depNode {
+	key := newDepKey(rtype, "")
+
+	return &depNode{
+		DependsOn: []*dependency{},
+		Edges:     map[depKey]*depNode{},
+		Key:       key,
+		Lifetime:  lifetime,
+		Type:      rtype,
+		TypeName:  key.String(),
+	}
+}
+
+// newGroupNode returns a node which collects the values of the members
+// of a group into a slice or a map. key is the key of the slice or map
+// type. The group itself is not cached, each member is cached according
+// to its own Lifetime
+func newGroupNode(key depKey) *depNode {
+	return &depNode{
+		DependsOn: []*dependency{},
+		Edges:     map[depKey]*depNode{},
+		Key:       key,
+		Lifetime:  PerDependency,
+		Members:   []*depNode{},
+		Type:      key.Type,
+		TypeName:  key.String(),
+	}
+}
+
 func (dn *depNode) AddEdge(node *depNode) {
 	for _, dependsOn := range dn.DependsOn {
-		if dependsOn == node.Type {
-			dn.Edges[dependsOn] = node
+		if dependsOn.Node == nil && dependsOn.Provider == nil && dependsOn.Key == node.Key {
+			dn.Edges[node.Key] = node
 			return
 		}
 	}
 }
 
-func (dn *depNode) CheckForCycle(seen []*depNode, checked map[*depNode]bool) error {
-	hasSeen := func(dn2 *depNode) bool {
-		for _, node := range seen {
-			if node == dn2 {
-				return true
-			}
+// Children returns all the nodes this node depends on directly, in the
+// order of its dependencies, followed by the members of a group
+func (dn *depNode) Children() []*depNode {
+	children := make([]*depNode, 0, len(dn.Edges)+len(dn.Members))
+
+	for _, dep := range dn.DependsOn {
+		node, hasNode := dn.Edges[dep.Key]
+		if dep.Provider != nil || hasNode == false || containsNode(children, node) {
+			continue
 		}
 
-		return false
+		children = append(children, node)
 	}
 
-	for _, node := range dn.Edges {
-		if checked[node] {
-			continue
-		}
+	return append(children, dn.Members...)
+}
 
-		if hasSeen(node) {
-			// print and return err
-			path := make([]string, len(seen), len(seen)+1)
-			for index, seenNode := range seen {
-				path[index] = seenNode.Type.String()
-			}
-			path = append(path, node.Type.String())
-			pathStr := strings.Join(path, "->")
-			return fmt.Errorf("di: circular dependency detected: %v", pathStr)
-		}
+// Args returns the parameters to call the constructor of the node with
+// from the values of its dependencies
+func (dn *depNode) Args(values []reflect.Value) []reflect.Value {
+	if dn.Injection == nil {
+		return values
+	}
+
+	return dn.Injection.Args(values)
+}
 
-		seenCopy := make([]*depNode, len(seen), len(seen)+1)
-		copy(seenCopy, seen)
-		seenCopy = append(seenCopy, node)
-		err := node.CheckForCycle(seenCopy, checked)
+// HasSameDeps returns true if the node has exactly the dependencies
+// specified, in the same order
+func (dn *depNode) HasSameDeps(deps []*dependency) bool {
+	if len(dn.DependsOn) != len(deps) {
+		return false
+	}
 
-		if err != nil {
-			return err
+	for index, dep := range deps {
+		if *dn.DependsOn[index] != *dep {
+			return false
 		}
 	}
 
-	checked[dn] = true
-	return nil
+	return true
+}
+
+// IsGroup returns true if the node collects the members of a group
+// or a map
+func (dn *depNode) IsGroup() bool {
+	return dn.Members != nil
 }
 
 func (dn *depNode) IsLeaf() bool {
@@ -105,7 +163,25 @@ func (dn *depNode) IsLeaf() bool {
 }
 
 func (dn *depNode) NewValue(ins []reflect.Value) (reflect.Value, error) {
-	outs := dn.Constructor.Call(ins)
+	if dn.IsGroup() {
+		if dn.Type.Kind() == reflect.Map {
+			value := reflect.MakeMapWithSize(dn.Type, len(ins))
+			for index, member := range dn.Members {
+				value.SetMapIndex(reflect.ValueOf(member.MapKey), ins[index])
+			}
+
+			return value, nil
+		}
+
+		return reflect.Append(reflect.MakeSlice(dn.Type, 0, len(ins)), ins...), nil
+	}
+
+	var outs []reflect.Value
+	if dn.Constructor.Type().IsVariadic() {
+		outs = dn.Constructor.CallSlice(ins)
+	} else {
+		outs = dn.Constructor.Call(ins)
+	}
 
 	var err error
 	if dn.ReturnsErr {
@@ -118,3 +194,109 @@ func (dn *depNode) NewValue(ins []reflect.Value) (reflect.Value, error) {
 
 	return outs[0], err
 }
+
+// dependentChain returns the types of a chain of definitions which lead
+// to node, ending with the definition which depends on node directly.
+// Where more than one definition depends on a node, the first of them in
+// the order of sortedNodes is used, followed by the members of groups and
+// the decorated definitions in the order of allNodes
+func dependentChain(deps map[depKey]*depNode, node *depNode) []reflect.Type {
+	chain := []reflect.Type{}
+	seen := map[*depNode]bool{node: true}
+	nodes := append(sortedNodes(deps), allNodes(deps)...)
+
+	for {
+		var dependent *depNode
+		for _, dep := range nodes {
+			if seen[dep] == false && dep.dependsOnNode(node) {
+				dependent = dep
+				break
+			}
+		}
+
+		if dependent == nil {
+			return chain
+		}
+
+		chain = append([]reflect.Type{dependent.Type}, chain...)
+		seen[dependent] = true
+		node = dependent
+	}
+}
+
+// dependsOnNode returns true if node is a dependency of this node, a
+// member of its group, or the definition it decorates
+func (dn *depNode) dependsOnNode(node *depNode) bool {
+	return dn.Edges[node.Key] == node || dn.Decorates == node || containsNode(dn.Members, node)
+}
+
+// allNodes returns every definition in deps sorted by type name, including
+// the members of groups and the definitions wrapped by decorators
+func allNodes(deps map[depKey]*depNode) []*depNode {
+	nodes := make([]*depNode, 0, len(deps))
+
+	for _, node := range sortedNodes(deps) {
+		members := []*depNode{node}
+		if node.IsGroup() {
+			members = node.Members
+		}
+
+		for _, member := range members {
+			for ; member != nil; member = member.Decorates {
+				nodes = append(nodes, member)
+			}
+		}
+	}
+
+	sort.SliceStable(nodes, func(i, j int) bool {
+		return nodes[i].TypeName < nodes[j].TypeName
+	})
+
+	return nodes
+}
+
+// containsNode returns true if node is in nodes
+func containsNode(nodes []*depNode, node *depNode) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedNodes returns the nodes of deps sorted by type name, and then by
+// package path for types with the same name. See pkgPath
+func sortedNodes(deps map[depKey]*depNode) []*depNode {
+	nodes := make([]*depNode, 0, len(deps))
+
+	for _, node := range deps {
+		nodes = append(nodes, node)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].TypeName != nodes[j].TypeName {
+			return nodes[i].TypeName < nodes[j].TypeName
+		}
+
+		return pkgPath(nodes[i].Type) < pkgPath(nodes[j].Type)
+	})
+
+	return nodes
+}
+
+// pkgPath returns the package path of rtype, or of its element type if
+// rtype is a pointer, slice, array, chan or map
+func pkgPath(rtype reflect.Type) string {
+	for rtype.PkgPath() == "" {
+		switch rtype.Kind() {
+		case reflect.Array, reflect.Chan, reflect.Map, reflect.Ptr, reflect.Slice:
+			rtype = rtype.Elem()
+		default:
+			return ""
+		}
+	}
+
+	return rtype.PkgPath()
+}
@@ -6,8 +6,19 @@ di supplies several dependency lifetime caching policies, provides dependency
 aware http handlers compatible with net/http, and provides a way to
 clean up dependencies instantiated during an http request.
 
-di only resolves dependencies which are interfaces, the resolver itself,
-http.ResponseWriter, and *http.Request.
+di resolves dependencies of any type which has a definition, the resolver
+itself, http.ResponseWriter, and *http.Request. A strict resolver only
+resolves interface definitions, see Options.
+
+A parameter of the form func() (Type, error) is a provider. Instead of the
+value of Type it receives a func which resolves Type from the same resolver
+when it is called, caching the value according to its Lifetime as usual:
+
+	func NewReport(db func() (DB, error)) Report { ... }
+
+Parameter tags, see Params, apply to Type. A provider does not count as a
+dependency when checking for circular dependencies, and provider types
+cannot themselves be defined.
 
 */
 package di
@@ -3,6 +3,7 @@ package di
 import (
 	"fmt"
 	"reflect"
+	"strings"
 )
 
 // ErrDefMissing is returned when an attempt is made to resolve
@@ -10,18 +11,37 @@ import (
 //
 // Implements the error interface
 type ErrDefMissing struct {
+	// Excluded are the conditions of the definitions of the type which
+	// were not added to the resolver because their condition was not
+	// met. See When
+	Excluded []string
+
+	// Name is the name of the definition which could not be resolved.
+	// Empty if the definition was not requested by name
+	Name string
+
 	// Type is the type of dependency which could not be resolved
 	Type reflect.Type
 }
 
 // newErrDefMissing creates and returns a new ErrDefMissing struct
-func newErrDefMissing(t reflect.Type) *ErrDefMissing {
+func newErrDefMissing(key depKey) *ErrDefMissing {
 	return &ErrDefMissing{
-		Type: t,
+		Name: key.Name,
+		Type: key.Type,
 	}
 }
 
 // Error returns an error string describing the error encountered
 func (edm *ErrDefMissing) Error() string {
-	return fmt.Sprintf("di: definition missing for type: %v", edm.Type)
+	msg := fmt.Sprintf("di: definition missing for type: %v", edm.Type)
+	if edm.Name != "" {
+		msg = fmt.Sprintf("%v, name: %v", msg, edm.Name)
+	}
+
+	if len(edm.Excluded) > 0 {
+		msg = fmt.Sprintf("%v, excluded by: %v", msg, strings.Join(edm.Excluded, ", "))
+	}
+
+	return msg
 }
@@ -9,7 +9,7 @@ import (
 func TestErrDefMissing(t *testing.T) {
 	a := NewA()
 	aType := reflect.TypeOf(a)
-	edm := newErrDefMissing(aType)
+	edm := newErrDefMissing(newDepKey(aType, ""))
 	es := edm.Error()
 	ts := aType.String()
 
@@ -22,4 +22,18 @@ func TestErrDefMissing(t *testing.T) {
 	if err == nil {
 		t.Fatal(err)
 	}
+
+	t.Run("Named", func(t *testing.T) {
+		const name = "replica"
+		edm := newErrDefMissing(newDepKey(aType, name))
+		es := edm.Error()
+
+		if edm.Name != name {
+			t.Fatal(edm.Name, name)
+		}
+
+		if strings.Contains(es, name) == false {
+			t.Fatal("was expecting the definition name in the error message", es)
+		}
+	})
 }
@@ -38,6 +38,12 @@ func newErrResolve(depChain []reflect.Type, err error, t reflect.Type) *ErrResol
 	}
 }
 
+// Error returns the same string as String, so that an *ErrResolve can
+// be returned as an error, such as from a provider func
+func (er *ErrResolve) Error() string {
+	return er.String()
+}
+
 // String returns an string describing the error encountered
 func (er *ErrResolve) String() string {
 	chain := append(er.DependencyChain, er.Type)
@@ -40,4 +40,11 @@ func TestErrResolve(t *testing.T) {
 			t.Fatal("expecting dep chain path", str, expected)
 		}
 	})
+	t.Run("Error", func(t *testing.T) {
+		var err error = newErrResolve(nil, errors.New("some_err"), depType)
+
+		if err.Error() != err.(*ErrResolve).String() {
+			t.Fatal(err.Error())
+		}
+	})
 }
@@ -0,0 +1,180 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Factory defines the func type ptrToFactory points to, built from
+// constructor. Some parameters of the constructor are supplied by the
+// caller of the factory each time it is called, and every other parameter
+// is injected by the resolver:
+//
+//	type SessionFactory func(userID int64) (Session, error)
+//
+//	func NewSession(userID int64, db DB) Session { ... }
+//
+//	defs := []*di.Def{
+//		{NewDB, di.Singleton},
+//		{di.Factory((*SessionFactory)(nil), NewSession), di.Singleton},
+//	}
+//
+// The parameters of the factory are matched to the parameters of the
+// constructor by type, in order. Every parameter of the factory must match
+// a parameter of the constructor. The factory must return the type returned
+// by the constructor, or a type it is assignable to, and must also return
+// an error if the constructor does.
+//
+// The constructor is called each time the factory is called. The Lifetime
+// of the Def is the Lifetime of the factory itself, and of the injected
+// values it uses. The injected parameters of the constructor may be tagged
+// with Params, any other annotation must be applied to the result of Factory
+func Factory(ptrToFactory interface{}, constructor interface{}) interface{} {
+	factoryType, err := ptrElem("Factory", ptrToFactory)
+	if err != nil {
+		return &annotatedFn{Err: err}
+	}
+
+	annotated, err := newAnnotatedFn(constructor)
+	if err != nil {
+		return &annotatedFn{Err: err}
+	}
+
+	factory, err := newFactoryFn(factoryType, annotated)
+	if err != nil {
+		return &annotatedFn{Err: err}
+	}
+
+	return factory
+}
+
+// newFactoryFn returns an annotated func which returns a factory of type
+// factoryType. Its parameters are the parameters of constructor which are
+// not parameters of the factory
+func newFactoryFn(factoryType reflect.Type, constructor *annotatedFn) (*annotatedFn, error) {
+	if factoryType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("di: Factory: %v is not a func type", factoryType)
+	}
+
+	if constructor.Name != "" || constructor.Group || constructor.Keyed || constructor.Decorator ||
+		constructor.Override || constructor.Alias || len(constructor.As) > 0 || constructor.IsInstance() {
+		return nil, fmt.Errorf("di: Factory: only Params may annotate the constructor of %v, annotate the factory instead", factoryType)
+	}
+
+	constructorType := constructor.Fn.Type()
+	isArg := make([]bool, constructorType.NumIn())
+	inTypes := make([]reflect.Type, 0, constructorType.NumIn())
+	params := make([]*paramTag, 0, constructorType.NumIn())
+	numArgs := 0
+
+	for index := range isArg {
+		inType := constructorType.In(index)
+
+		if numArgs < factoryType.NumIn() && factoryType.In(numArgs) == inType {
+			isArg[index] = true
+			numArgs += 1
+			continue
+		}
+
+		var tag *paramTag
+		if index < len(constructor.Params) {
+			tag = constructor.Params[index]
+		}
+
+		inTypes = append(inTypes, inType)
+		params = append(params, tag)
+	}
+
+	if numArgs < factoryType.NumIn() {
+		return nil, fmt.Errorf("di: Factory: parameter %v of %v does not match a parameter of the constructor %v", numArgs+1, factoryType, constructorType)
+	}
+
+	err := verifyFactoryOut(factoryType, constructorType)
+	if err != nil {
+		return nil, err
+	}
+
+	constructorValue := constructor.Fn
+	fnType := reflect.FuncOf(inTypes, []reflect.Type{factoryType}, false)
+	fn := reflect.MakeFunc(fnType, func(injected []reflect.Value) []reflect.Value {
+		factory := reflect.MakeFunc(factoryType, func(args []reflect.Value) []reflect.Value {
+			ins := make([]reflect.Value, len(isArg))
+			argIndex, injectedIndex := 0, 0
+			for index := range ins {
+				if isArg[index] {
+					ins[index] = args[argIndex]
+					argIndex += 1
+				} else {
+					ins[index] = injected[injectedIndex]
+					injectedIndex += 1
+				}
+			}
+
+			var outs []reflect.Value
+			if constructorType.IsVariadic() {
+				outs = constructorValue.CallSlice(ins)
+			} else {
+				outs = constructorValue.Call(ins)
+			}
+
+			results := make([]reflect.Value, factoryType.NumOut())
+			results[0] = reflect.New(factoryType.Out(0)).Elem()
+			results[0].Set(outs[0])
+
+			if len(results) == 2 {
+				results[1] = reflect.Zero(errorType)
+				if len(outs) == 2 && isNilErr(outs[1]) == false {
+					results[1] = outs[1]
+				}
+			}
+
+			return results
+		})
+
+		return []reflect.Value{factory}
+	})
+
+	return &annotatedFn{
+		Factory: constructorValue,
+		Fn:      fn,
+		Params:  params,
+	}, nil
+}
+
+// isNilErr returns true if err, an error returned by a constructor, is
+// nil. A constructor may return a nil pointer to its own error type, which
+// would not be a nil error once returned by a factory
+func isNilErr(err reflect.Value) bool {
+	switch err.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+		return err.IsNil()
+	}
+
+	return false
+}
+
+// verifyFactoryOut returns an error if the return values of a factory
+// of type factoryType cannot be built from the return values of its
+// constructor
+func verifyFactoryOut(factoryType, constructorType reflect.Type) error {
+	numOut := factoryType.NumOut()
+	constructorNumOut := constructorType.NumOut()
+
+	if constructorNumOut == 0 || constructorNumOut > 2 || (constructorNumOut == 2 && constructorType.Out(1).Implements(errType) == false) {
+		return fmt.Errorf("di: Factory: constructor %v must return a value, and optionally an error", constructorType)
+	}
+
+	if numOut == 0 || numOut > 2 || (numOut == 2 && factoryType.Out(1) != errorType) {
+		return fmt.Errorf("di: Factory: %v must return a value, and optionally an error", factoryType)
+	}
+
+	if constructorType.Out(0).AssignableTo(factoryType.Out(0)) == false {
+		return fmt.Errorf("di: Factory: %v cannot return %v", factoryType, constructorType.Out(0))
+	}
+
+	if constructorNumOut == 2 && numOut == 1 {
+		return fmt.Errorf("di: Factory: %v must return an error, %v returns an error", factoryType, constructorType)
+	}
+
+	return nil
+}
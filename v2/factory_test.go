@@ -0,0 +1,139 @@
+package di
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type aFactory func(a int) (A, error)
+
+type aFactoryNoErr func(a int) A
+
+type factoryErr struct{}
+
+func (fe *factoryErr) Error() string { return "factoryErr" }
+
+func TestFactory(t *testing.T) {
+	newA := func(a int, b B) A {
+		b1, b2 := b.B()
+		return &aImpl{a + b1 + b2}
+	}
+	newB := func() B { return &bImpl{10, 20} }
+
+	t.Run("Factory", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Factory((*aFactory)(nil), newA), Singleton},
+			{newB, Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var factory aFactory
+		resolveErr := resolver.Resolve(&factory)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		a1, err := factory(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		a2, err := factory(2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if a1.A() != 31 || a2.A() != 32 {
+			t.Fatal(a1.A(), a2.A())
+		}
+
+		infos := resolver.Definitions()
+		if infos[1].Factory == false || infos[1].DependsOn[0] != "di.B" || strings.Contains(infos[1].Constructor, "TestFactory") == false {
+			t.Fatal(infos[1])
+		}
+	})
+	t.Run("Err", func(t *testing.T) {
+		newAErr := func(a int) (A, error) { return nil, errors.New("newAErr") }
+		resolver, err := resolverChildNew([]*Def{{Factory((*aFactory)(nil), newAErr), Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resolveErr := resolver.Invoke(func(factory aFactory) {
+			_, err = factory(1)
+		})
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if err == nil || err.Error() != "newAErr" {
+			t.Fatal(err)
+		}
+	})
+	t.Run("NilErr", func(t *testing.T) {
+		newAErr := func(a int) (A, *factoryErr) { return &aImpl{a}, nil }
+		resolver, err := resolverChildNew([]*Def{{Factory((*aFactory)(nil), newAErr), Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Invoke(func(factory aFactory) {
+			a, err = factory(1)
+		})
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if err != nil || a.A() != 1 {
+			t.Fatal("expecting a nil *factoryErr to be a nil error", err)
+		}
+	})
+	t.Run("Params", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Named("b", newB), Singleton},
+			{Factory((*aFactoryNoErr)(nil), Params(newA, "", "name=b")), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var factory aFactoryNoErr
+		resolveErr := resolver.Resolve(&factory)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a := factory(3); a.A() != 33 {
+			t.Fatal(a.A())
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		newAErr := func(a int) (A, error) { return nil, nil }
+		factories := []interface{}{
+			Factory((*aFactory)(nil), func(b B) A { return nil }),
+			Factory((*aFactoryNoErr)(nil), newAErr),
+			Factory((*aFactory)(nil), func(a int) B { return nil }),
+			Factory((*A)(nil), newA),
+			Factory((*aFactory)(nil), Named("a", newA)),
+			Factory(aFactory(nil), newA),
+		}
+
+		for index, factory := range factories {
+			_, err := resolverChildNew([]*Def{{factory, Singleton}})
+
+			if err == nil {
+				t.Fatal("expecting invalid factory err", index)
+			}
+		}
+	})
+}
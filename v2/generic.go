@@ -0,0 +1,138 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Provide returns a new *Def for constructor. Unlike a Def literal the
+// type of the constructor is checked by the compiler, and the type it is
+// defined as may be stated explicitly:
+//
+//	defs := []*di.Def{
+//		di.Provide[Config](NewConfig, di.Singleton),
+//		di.Provide1[Store](NewStore, di.Singleton),
+//		di.ProvideErr2[Service](NewService, di.PerHttpRequest),
+//	}
+//
+// Provide1, Provide2, and Provide3 are the same as Provide for
+// constructors with parameters, and the ProvideErr funcs are the same for
+// constructors which also return an error. Each parameter is injected as
+// usual. Constructors with more parameters, or which need to be annotated,
+// can be defined with a Def literal
+func Provide[T any](constructor func() T, lifetime Lifetime) *Def {
+	return &Def{constructor, lifetime}
+}
+
+// Provide1 is the same as Provide for a constructor with one parameter
+func Provide1[T, A any](constructor func(A) T, lifetime Lifetime) *Def {
+	return &Def{constructor, lifetime}
+}
+
+// Provide2 is the same as Provide for a constructor with two parameters
+func Provide2[T, A, B any](constructor func(A, B) T, lifetime Lifetime) *Def {
+	return &Def{constructor, lifetime}
+}
+
+// Provide3 is the same as Provide for a constructor with three parameters
+func Provide3[T, A, B, C any](constructor func(A, B, C) T, lifetime Lifetime) *Def {
+	return &Def{constructor, lifetime}
+}
+
+// ProvideErr is the same as Provide for a constructor which returns an error
+func ProvideErr[T any](constructor func() (T, error), lifetime Lifetime) *Def {
+	return &Def{constructor, lifetime}
+}
+
+// ProvideErr1 is the same as Provide1 for a constructor which returns an
+// error
+func ProvideErr1[T, A any](constructor func(A) (T, error), lifetime Lifetime) *Def {
+	return &Def{constructor, lifetime}
+}
+
+// ProvideErr2 is the same as Provide2 for a constructor which returns an
+// error
+func ProvideErr2[T, A, B any](constructor func(A, B) (T, error), lifetime Lifetime) *Def {
+	return &Def{constructor, lifetime}
+}
+
+// ProvideErr3 is the same as Provide3 for a constructor which returns an
+// error
+func ProvideErr3[T, A, B, C any](constructor func(A, B, C) (T, error), lifetime Lifetime) *Def {
+	return &Def{constructor, lifetime}
+}
+
+// Resolve resolves a value of type T from r. See IResolver.Resolve
+//
+//	store, err := di.Resolve[Store](resolver)
+func Resolve[T any](r IResolver) (T, error) {
+	return ResolveNamed[T](r, "")
+}
+
+// ResolveNamed resolves the value of type T registered under name from
+// r. See IResolver.ResolveNamed
+func ResolveNamed[T any](r IResolver, name string) (T, error) {
+	var value T
+	err := r.ResolveNamed(name, &value)
+
+	if err != nil {
+		return value, err
+	}
+
+	return value, nil
+}
+
+// Curry curries fn with r, returning the curried func as type F. An error
+// is returned if the curried func is not of type F. See IResolver.Curry
+//
+//	curryFoo, err := di.Curry[func(int) int](resolver, foo)
+func Curry[F any](r IResolver, fn interface{}) (F, error) {
+	var curried F
+	curriedFn, resolveErr := r.Curry(fn)
+
+	if resolveErr != nil {
+		return curried, resolveErr
+	}
+
+	curried, isF := curriedFn.(F)
+	if isF == false {
+		return curried, fmt.Errorf("di: Curry: curried func %v is not of type %v", reflect.TypeOf(curriedFn), reflect.TypeOf(&curried).Elem())
+	}
+
+	return curried, nil
+}
+
+// Invoke1 resolves the parameter of fn from r, calls fn, and returns its
+// result. See IResolver.Invoke
+//
+//	report, err := di.Invoke1(resolver, func(store Store) Report { ... })
+func Invoke1[A, R any](r IResolver, fn func(A) R) (R, error) {
+	var result R
+	err := invoke(r, func(a A) { result = fn(a) })
+	return result, err
+}
+
+// Invoke2 is the same as Invoke1 for a func with two parameters
+func Invoke2[A, B, R any](r IResolver, fn func(A, B) R) (R, error) {
+	var result R
+	err := invoke(r, func(a A, b B) { result = fn(a, b) })
+	return result, err
+}
+
+// Invoke3 is the same as Invoke1 for a func with three parameters
+func Invoke3[A, B, C, R any](r IResolver, fn func(A, B, C) R) (R, error) {
+	var result R
+	err := invoke(r, func(a A, b B, c C) { result = fn(a, b, c) })
+	return result, err
+}
+
+// invoke calls r.Invoke, returning an untyped nil error on success
+func invoke(r IResolver, fn interface{}) error {
+	err := r.Invoke(fn)
+
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package di
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestGeneric(t *testing.T) {
+	newA := func() A { return &aImpl{1} }
+	newB := func(a A) B { return &bImpl{a.A(), a.A()} }
+	newC := func(A, B) (C, error) { return nil, errors.New("newC") }
+	resolver, err := resolverChildNew([]*Def{
+		Provide(newA, Singleton),
+		Provide1(newB, PerDependency),
+		ProvideErr2(newC, PerDependency),
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Resolve", func(t *testing.T) {
+		b, err := Resolve[B](resolver)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if a1, _ := b.B(); a1 != 1 {
+			t.Fatal(a1)
+		}
+
+		_, err = Resolve[C](resolver)
+		if err == nil || err.(*ErrResolve).Err.Error() != "newC" {
+			t.Fatal(err)
+		}
+
+		_, err = ResolveNamed[A](resolver, "missing")
+		if err == nil {
+			t.Fatal("expecting err resolving a missing definition")
+		}
+	})
+	t.Run("Curry", func(t *testing.T) {
+		curried, err := Curry[func(int) int](resolver, func(i int, a A) int { return i + a.A() })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if curried(4) != 5 {
+			t.Fatal(curried(4))
+		}
+
+		_, err = Curry[func() int](resolver, func(i int, a A) int { return i })
+		if err == nil {
+			t.Fatal("expecting err currying to the wrong func type")
+		}
+	})
+	t.Run("Invoke", func(t *testing.T) {
+		sum, err := Invoke2(resolver, func(a A, b B) int {
+			b1, b2 := b.B()
+			return a.A() + b1 + b2
+		})
+
+		if err != nil || sum != 3 {
+			t.Fatal(sum, err)
+		}
+
+		_, err = Invoke1(resolver, func(c C) int { return 0 })
+		if err == nil {
+			t.Fatal("expecting err invoking with a dependency which cannot be resolved")
+		}
+	})
+}
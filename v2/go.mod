@@ -1,3 +1,3 @@
 module github.com/clavoie/di/v2
 
-go 1.14
+go 1.18
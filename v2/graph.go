@@ -0,0 +1,298 @@
+package di
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GraphFormat is a format the dependency graph of a resolver can be
+// written in. See IHttpResolver.WriteGraph
+type GraphFormat int
+
+const (
+	// GraphDOT writes the graph in the Graphviz DOT language
+	GraphDOT GraphFormat = iota
+
+	// GraphMermaid writes the graph as a Mermaid flowchart
+	GraphMermaid
+
+	// GraphJSON writes the graph as a JSON object with a list of nodes
+	// and a list of edges:
+	//
+	//	{
+	//		"nodes": [{"id": "n0", "kind": "definition", "type": "di.A", "lifetime": "Singleton", "constructor": "pkg.NewA"}],
+	//		"edges": [{"from": "n1", "to": "n0", "optional": false, "provider": false}]
+	//	}
+	//
+	// The kind of a node is one of definition, decorator, group, handler,
+	// builtin, or missing. Only definitions, decorators, and groups have a
+	// lifetime
+	GraphJSON
+)
+
+// graphColors are the fill colors of graph nodes, by Lifetime or by kind
+// for nodes without a Lifetime
+var graphColors = map[string]string{
+	Singleton.String():      "#a6cee3",
+	PerDependency.String():  "#b2df8a",
+	PerHttpRequest.String(): "#fb9a99",
+	PerResolve.String():     "#fdbf6f",
+	"handler":               "#cab2d6",
+	"builtin":               "#eeeeee",
+	"missing":               "#ffffff",
+}
+
+// graphNode is a node of a graph written by WriteGraph
+type graphNode struct {
+	ID          string `json:"id"`
+	Kind        string `json:"kind"`
+	Type        string `json:"type"`
+	Lifetime    string `json:"lifetime,omitempty"`
+	Constructor string `json:"constructor,omitempty"`
+}
+
+// Class returns the name of the class the node is colored by
+func (gn *graphNode) Class() string {
+	if gn.Lifetime != "" {
+		return gn.Lifetime
+	}
+
+	return gn.Kind
+}
+
+// Label returns the label the node is displayed with
+func (gn *graphNode) Label() string {
+	if gn.Lifetime == "" {
+		return gn.Type
+	}
+
+	return fmt.Sprintf("%v\n%v", gn.Type, gn.Lifetime)
+}
+
+// graphEdge is an edge of a graph written by WriteGraph, from a node to
+// one of its dependencies
+type graphEdge struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Optional bool   `json:"optional"`
+	Provider bool   `json:"provider"`
+}
+
+// graph is the dependency graph of a resolver, with its nodes and edges
+// in a deterministic order
+type graph struct {
+	Nodes []*graphNode `json:"nodes"`
+	Edges []*graphEdge `json:"edges"`
+
+	// ids are the ids of the nodes of each definition
+	ids map[*depNode]string
+
+	// keyIds are the ids of the builtin and missing nodes, by key
+	keyIds map[depKey]string
+}
+
+// newGraph returns the graph of the definitions in deps and of the http
+// handlers registered with the resolver
+func newGraph(deps map[depKey]*depNode, handlers []*httpHandlerRoot) *graph {
+	g := &graph{
+		Nodes:  make([]*graphNode, 0, len(deps)),
+		Edges:  make([]*graphEdge, 0),
+		ids:    make(map[*depNode]string, len(deps)),
+		keyIds: make(map[depKey]string),
+	}
+
+	nodes := sortedNodes(deps)
+	defs := make([]*depNode, 0, len(nodes))
+	for _, node := range nodes {
+		for _, member := range append([]*depNode{node}, node.Members...) {
+			for ; member != nil; member = member.Decorates {
+				defs = append(defs, member)
+				g.addDef(member)
+			}
+		}
+	}
+
+	for _, node := range defs {
+		for _, member := range node.Members {
+			g.addEdge(g.ids[node], g.ids[member], nil)
+		}
+
+		for _, dep := range node.DependsOn {
+			target, hasTarget := dep.Node, dep.Node != nil
+			if hasTarget == false {
+				target, hasTarget = deps[dep.Key]
+			}
+
+			if hasTarget {
+				g.addEdge(g.ids[node], g.ids[target], dep)
+			} else {
+				g.addEdge(g.ids[node], g.keyID(dep.Key), dep)
+			}
+		}
+	}
+
+	sortedHandlers := append([]*httpHandlerRoot{}, handlers...)
+	sort.SliceStable(sortedHandlers, func(i, j int) bool {
+		return sortedHandlers[i].Name < sortedHandlers[j].Name
+	})
+
+	for _, handler := range sortedHandlers {
+		id := g.addNode(&graphNode{Kind: "handler", Type: handler.Name})
+
+		for _, dep := range handler.Deps {
+			if target, hasTarget := deps[dep.Key]; hasTarget {
+				g.addEdge(id, g.ids[target], dep)
+			} else {
+				g.addEdge(id, g.keyID(dep.Key), dep)
+			}
+		}
+	}
+
+	return g
+}
+
+// addNode adds a node to the graph, returning its id
+func (g *graph) addNode(node *graphNode) string {
+	node.ID = fmt.Sprintf("n%v", len(g.Nodes))
+	g.Nodes = append(g.Nodes, node)
+	return node.ID
+}
+
+// addDef adds a node for a definition to the graph
+func (g *graph) addDef(node *depNode) {
+	gn := &graphNode{
+		Kind:     "definition",
+		Lifetime: node.Lifetime.String(),
+		Type:     node.TypeName,
+	}
+
+	switch {
+	case node.IsGroup():
+		gn.Kind = "group"
+	case node.Decorates != nil:
+		gn.Kind = "decorator"
+		gn.Constructor = funcName(node.Constructor)
+	default:
+		gn.Constructor = newDefInfo(node).Constructor
+	}
+
+	g.ids[node] = g.addNode(gn)
+}
+
+// addEdge adds an edge to the graph. dep is the dependency the edge was
+// created for, if any
+func (g *graph) addEdge(from, to string, dep *dependency) {
+	edge := &graphEdge{From: from, To: to}
+
+	if dep != nil {
+		edge.Optional = dep.Optional
+		edge.Provider = dep.Provider != nil
+	}
+
+	g.Edges = append(g.Edges, edge)
+}
+
+// keyID returns the id of the node for a key which has no definition,
+// adding the node if it does not exist yet. Types supplied by the resolver
+// are builtin, any other type is missing
+func (g *graph) keyID(key depKey) string {
+	if id, hasID := g.keyIds[key]; hasID {
+		return id
+	}
+
+	kind := "missing"
+	if isBuiltin(key) {
+		kind = "builtin"
+	}
+
+	id := g.addNode(&graphNode{Kind: kind, Type: key.String()})
+	g.keyIds[key] = id
+	return id
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language
+func (g *graph) WriteDOT(w io.Writer) error {
+	lines := []string{
+		"digraph di {",
+		"\tnode [shape=box, style=filled];",
+	}
+
+	for _, node := range g.Nodes {
+		lines = append(lines, fmt.Sprintf("\t%v [label=%v, fillcolor=%q];", node.ID, strconv.Quote(node.Label()), graphColors[node.Class()]))
+	}
+
+	for _, edge := range g.Edges {
+		style := ""
+		if edge.Optional || edge.Provider {
+			style = " [style=dashed]"
+		}
+
+		lines = append(lines, fmt.Sprintf("\t%v -> %v%v;", edge.From, edge.To, style))
+	}
+
+	lines = append(lines, "}")
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart
+func (g *graph) WriteMermaid(w io.Writer) error {
+	lines := []string{"flowchart LR"}
+	classes := make(map[string]bool)
+
+	for _, node := range g.Nodes {
+		label := strings.NewReplacer(`"`, "#quot;", "\n", "<br/>").Replace(node.Label())
+		lines = append(lines, fmt.Sprintf("\t%v[\"%v\"]:::%v", node.ID, label, node.Class()))
+		classes[node.Class()] = true
+	}
+
+	for _, edge := range g.Edges {
+		arrow := "-->"
+		if edge.Optional || edge.Provider {
+			arrow = "-.->"
+		}
+
+		lines = append(lines, fmt.Sprintf("\t%v %v %v", edge.From, arrow, edge.To))
+	}
+
+	classNames := make([]string, 0, len(classes))
+	for class := range classes {
+		classNames = append(classNames, class)
+	}
+
+	sort.Strings(classNames)
+	for _, class := range classNames {
+		lines = append(lines, fmt.Sprintf("\tclassDef %v fill:%v", class, graphColors[class]))
+	}
+
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// WriteJSON writes the graph as JSON. See GraphJSON
+func (g *graph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g)
+}
+
+func (c *resolverParent) WriteGraph(w io.Writer, format GraphFormat) error {
+	c.handlersLock.Lock()
+	g := newGraph(c.allDeps, c.handlers)
+	c.handlersLock.Unlock()
+
+	switch format {
+	case GraphDOT:
+		return g.WriteDOT(w)
+	case GraphMermaid:
+		return g.WriteMermaid(w)
+	case GraphJSON:
+		return g.WriteJSON(w)
+	}
+
+	return fmt.Errorf("di: WriteGraph: unknown format: %v", format)
+}
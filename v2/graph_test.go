@@ -0,0 +1,197 @@
+package di
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func graphTestHandler(b B, w http.ResponseWriter) {}
+
+func TestGraph(t *testing.T) {
+	newResolver := func(t *testing.T) IHttpResolver {
+		resolver, err := NewResolver(resolverParentErr, []*Def{
+			{NewA, Singleton},
+			{NewB, PerResolve},
+			{NewDependsOnHttp, PerHttpRequest},
+			{NewSubDepNotFound, PerDependency},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = resolver.HttpHandler(graphTestHandler)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return resolver
+	}
+	writeGraph := func(t *testing.T, resolver IHttpResolver, format GraphFormat) string {
+		buf := new(bytes.Buffer)
+		err := resolver.WriteGraph(buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return buf.String()
+	}
+
+	t.Run("DOT", func(t *testing.T) {
+		expected := `digraph di {
+	node [shape=box, style=filled];
+	n0 [label="di.A\nSingleton", fillcolor="#a6cee3"];
+	n1 [label="di.B\nPerResolve", fillcolor="#fdbf6f"];
+	n2 [label="di.DependsOnHttp\nPerHttpRequest", fillcolor="#fb9a99"];
+	n3 [label="di.SubDepNotFound\nPerDependency", fillcolor="#b2df8a"];
+	n4 [label="http.ResponseWriter", fillcolor="#eeeeee"];
+	n5 [label="*http.Request", fillcolor="#eeeeee"];
+	n6 [label="di.SubDep", fillcolor="#ffffff"];
+	n7 [label="github.com/clavoie/di/v2.graphTestHandler", fillcolor="#cab2d6"];
+	n1 -> n0;
+	n1 -> n0;
+	n2 -> n4;
+	n2 -> n5;
+	n3 -> n6;
+	n7 -> n1;
+	n7 -> n4;
+}
+`
+		actual := writeGraph(t, newResolver(t), GraphDOT)
+		if actual != expected {
+			t.Fatal("unexpected DOT:\n", actual)
+		}
+	})
+	t.Run("Mermaid", func(t *testing.T) {
+		expected := `flowchart LR
+	n0["di.A<br/>Singleton"]:::Singleton
+	n1["di.B<br/>PerResolve"]:::PerResolve
+	n2["di.DependsOnHttp<br/>PerHttpRequest"]:::PerHttpRequest
+	n3["di.SubDepNotFound<br/>PerDependency"]:::PerDependency
+	n4["http.ResponseWriter"]:::builtin
+	n5["*http.Request"]:::builtin
+	n6["di.SubDep"]:::missing
+	n7["github.com/clavoie/di/v2.graphTestHandler"]:::handler
+	n1 --> n0
+	n1 --> n0
+	n2 --> n4
+	n2 --> n5
+	n3 --> n6
+	n7 --> n1
+	n7 --> n4
+	classDef PerDependency fill:#b2df8a
+	classDef PerHttpRequest fill:#fb9a99
+	classDef PerResolve fill:#fdbf6f
+	classDef Singleton fill:#a6cee3
+	classDef builtin fill:#eeeeee
+	classDef handler fill:#cab2d6
+	classDef missing fill:#ffffff
+`
+		actual := writeGraph(t, newResolver(t), GraphMermaid)
+		if actual != expected {
+			t.Fatal("unexpected Mermaid:\n", actual)
+		}
+	})
+	t.Run("JSON", func(t *testing.T) {
+		var g struct {
+			Nodes []*graphNode
+			Edges []*graphEdge
+		}
+
+		err := json.Unmarshal([]byte(writeGraph(t, newResolver(t), GraphJSON)), &g)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(g.Nodes) != 8 || len(g.Edges) != 7 {
+			t.Fatal("unexpected graph size", len(g.Nodes), len(g.Edges))
+		}
+
+		b := g.Nodes[1]
+		if b.ID != "n1" || b.Kind != "definition" || b.Type != "di.B" || b.Lifetime != "PerResolve" || b.Constructor != "github.com/clavoie/di/v2.NewB" {
+			t.Fatal("unexpected node", b)
+		}
+
+		handler := g.Nodes[7]
+		if handler.Kind != "handler" || handler.Lifetime != "" {
+			t.Fatal("unexpected handler", handler)
+		}
+
+		if *g.Edges[5] != (graphEdge{From: "n7", To: "n1"}) {
+			t.Fatal("unexpected edge", g.Edges[5])
+		}
+	})
+	t.Run("Deterministic", func(t *testing.T) {
+		for _, format := range []GraphFormat{GraphDOT, GraphMermaid, GraphJSON} {
+			expected := writeGraph(t, newResolver(t), format)
+
+			for i := 0; i < 20; i += 1 {
+				if writeGraph(t, newResolver(t), format) != expected {
+					t.Fatal("graph is not deterministic", format)
+				}
+			}
+		}
+	})
+	t.Run("Groups", func(t *testing.T) {
+		resolver, err := NewResolver(resolverParentErr, []*Def{
+			{Group(func() Plugin { return &pluginImpl{1} }), Singleton},
+			{Group(func() Plugin { return &pluginImpl{2} }), Singleton},
+			{NewPlugins, PerDependency},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := writeGraph(t, resolver, GraphDOT)
+		if strings.Contains(actual, `label="[]di.Plugin\nPerDependency"`) == false {
+			t.Fatal("expecting group node", actual)
+		}
+
+		if strings.Count(actual, `label="di.Plugin\nSingleton"`) != 2 {
+			t.Fatal("expecting member nodes", actual)
+		}
+	})
+	t.Run("OptionalAndProvider", func(t *testing.T) {
+		type params struct {
+			In
+			A  A
+			SD SubDep `di:"optional"`
+		}
+
+		resolver, err := NewResolver(resolverParentErr, []*Def{
+			{NewA, Singleton},
+			{func(p params) C { return nil }, PerDependency},
+			{func(a func() (A, error)) D { return nil }, PerDependency},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := writeGraph(t, resolver, GraphMermaid)
+		if strings.Count(actual, "-.->") != 2 || strings.Count(actual, " --> ") != 1 {
+			t.Fatal("unexpected edges", actual)
+		}
+	})
+	t.Run("SetDefaultServeMux", func(t *testing.T) {
+		resolver := newResolver(t)
+		err := resolver.SetDefaultServeMux([]*HttpDef{
+			{graphTestHandler, "/graph/test"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := writeGraph(t, resolver, GraphDOT)
+		if strings.Contains(actual, `[label="/graph/test", fillcolor="#cab2d6"]`) == false {
+			t.Fatal("expecting pattern root", actual)
+		}
+	})
+	t.Run("UnknownFormat", func(t *testing.T) {
+		err := newResolver(t).WriteGraph(new(bytes.Buffer), GraphFormat(100))
+		if err == nil {
+			t.Fatal("expecting error")
+		}
+	})
+}
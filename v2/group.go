@@ -0,0 +1,21 @@
+package di
+
+// Group adds the value returned by constructor to a group of definitions
+// of the same type, instead of registering it as the only definition
+// of that type. Use the result as the Constructor of a Def:
+//
+//	defs := []*di.Def{
+//		{di.Group(NewAuthMiddleware), di.Singleton},
+//		{di.Group(NewLogMiddleware), di.PerHttpRequest},
+//	}
+//
+// A parameter of type []T, or a variadic ...T, receives one value from each
+// member of the group of T, in the order they were defined. Each member is
+// resolved according to its own Lifetime. Groups may be combined with Named,
+// in which case the parameter must ask for the group by name, see Params
+func Group(constructor interface{}) interface{} {
+	return annotate(constructor, func(af *annotatedFn) error {
+		af.Group = true
+		return nil
+	})
+}
@@ -0,0 +1,208 @@
+package di
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestGroup(t *testing.T) {
+	pluginCounter := 0
+	newPlugin := func() Plugin {
+		pluginCounter += 1
+		return &pluginImpl{pluginCounter}
+	}
+	newPlugin1 := func() Plugin { return &pluginImpl{-1} }
+
+	t.Run("Slice", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Group(newPlugin1), Singleton},
+			{Group(newPlugin), PerDependency},
+			{NewPlugins, PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var plugins1, plugins2 Plugins
+		resolveErr := resolver.Resolve(&plugins1)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		resolveErr = resolver.Resolve(&plugins2)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		ps1, ps2 := plugins1.Plugins(), plugins2.Plugins()
+		if len(ps1) != 2 || len(ps2) != 2 {
+			t.Fatal(len(ps1), len(ps2))
+		}
+
+		if ps1[0].Plugin() != -1 || ps1[0] != ps2[0] {
+			t.Fatal("expecting singleton member first, and shared between resolves")
+		}
+
+		if ps1[1] == ps2[1] {
+			t.Fatal("expecting a new per dependency member for each resolve")
+		}
+	})
+	t.Run("Variadic", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Group(newPlugin1), Singleton},
+			{Group(newPlugin), PerDependency},
+			{NewPluginsVariadic, PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var plugins Plugins
+		resolveErr := resolver.Resolve(&plugins)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if len(plugins.Plugins()) != 2 {
+			t.Fatal(plugins.Plugins())
+		}
+
+		count := 0
+		handler, err := resolver.HttpHandler(func(w http.ResponseWriter, ps ...Plugin) { count = len(ps) })
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		handler(new(TestResponseWriter), new(http.Request))
+		if count != 2 {
+			t.Fatal(count)
+		}
+	})
+	t.Run("Named", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Group(newPlugin1), Singleton},
+			{Named("other", Group(newPlugin)), Singleton},
+			{Named("other", Group(newPlugin)), Singleton},
+			{Params(NewPlugins, "name=other"), PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var plugins Plugins
+		resolveErr := resolver.Resolve(&plugins)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		ps := plugins.Plugins()
+		if len(ps) != 2 || ps[0].Plugin() == -1 || ps[0] == ps[1] {
+			t.Fatal(ps)
+		}
+	})
+	t.Run("Joined_InOrder", func(t *testing.T) {
+		defs1 := newDefCollection()
+		err := defs1.Add(Group(func() Plugin { return &pluginImpl{1} }), Singleton)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defs2 := newDefCollection()
+		err = defs2.Add(Group(func() Plugin { return &pluginImpl{2} }), Singleton)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = defs2.Add(Group(func() Plugin { return &pluginImpl{3} }), Singleton)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		deps, err := joinDefCollection(defs1, defs2).build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		group := deps[newDepKey(pluginSliceType, "")]
+		if group == nil || len(group.Members) != 3 {
+			t.Fatal(group)
+		}
+
+		for index, member := range group.Members {
+			value, _ := member.NewValue(nil)
+			if id := value.Interface().(Plugin).Plugin(); id != index+1 {
+				t.Fatal(index, id)
+			}
+		}
+	})
+	t.Run("Missing", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{NewPlugins, PerDependency}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var plugins Plugins
+		resolveErr := resolver.Resolve(&plugins)
+		if resolveErr == nil {
+			t.Fatal("expecting missing group err")
+		}
+
+		if _, isMissingErr := resolveErr.Err.(*ErrDefMissing); isMissingErr == false {
+			t.Fatal(resolveErr.Err)
+		}
+	})
+	t.Run("Concurrent", func(t *testing.T) {
+		var created int32
+		newCountedPlugin := func() Plugin {
+			return &pluginImpl{int(atomic.AddInt32(&created, 1))}
+		}
+
+		resolver, err := resolverChildNew([]*Def{
+			{Group(newCountedPlugin), Singleton},
+			{Group(newCountedPlugin), Singleton},
+			{newCountedPlugin, Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan *ErrResolve, 50)
+		for index := 0; index < 50; index += 1 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- resolver.Invoke(func([]Plugin, Plugin) {})
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+		for resolveErr := range errs {
+			if resolveErr != nil {
+				t.Fatal(resolveErr)
+			}
+		}
+
+		if created != 3 {
+			t.Fatal("expecting each singleton to be created once", created)
+		}
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{
+			{Group(func(Plugins) Plugin { return nil }), Singleton},
+			{NewPlugins, Singleton},
+		})
+
+		if err == nil {
+			t.Fatal("expecting circular dependency err")
+		}
+	})
+}
@@ -7,3 +7,8 @@ import (
 
 var requestType = reflect.TypeOf((**http.Request)(nil)).Elem()
 var responseWriterType = reflect.TypeOf((*http.ResponseWriter)(nil)).Elem()
+
+// requestNode and responseWriterNode are the cache keys under which
+// the *http.Request and http.ResponseWriter of an http request are stored
+var requestNode = newBuiltinNode(requestType, PerHttpRequest)
+var responseWriterNode = newBuiltinNode(responseWriterType, PerHttpRequest)
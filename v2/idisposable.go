@@ -0,0 +1,12 @@
+package di
+
+import "context"
+
+// IDisposable is an interface a Singleton can implement if it would like
+// a callback executed when the resolver which created it is closed. A
+// Singleton which implements io.Closer is closed in the same way
+type IDisposable interface {
+	// Di_Dispose is called once when the resolver which created the
+	// implementing object is closed, with the context passed to Close
+	Di_Dispose(ctx context.Context) error
+}
@@ -1,12 +1,33 @@
 package di
 
-import "net/http"
+import (
+	"context"
+	"io"
+	"net/http"
+)
 
 // IHttpResolver is an IResolver which can also generate http request
 // handlers that resolve their dependencies
 type IHttpResolver interface {
 	IResolver
 
+	// Close disposes of every Singleton created by the resolver which
+	// implements IDisposable or io.Closer. Singletons are disposed of in
+	// reverse dependency order, so each is disposed of before the
+	// Singletons it depends on. Instances are not disposed of, they are
+	// owned by the caller.
+	//
+	// If the ctx is done before every Singleton is disposed of the rest
+	// are not disposed of. Every error encountered is returned in an
+	// *ErrClose. Once Close is called resolving any value returns
+	// ErrClosed, and calling Close again does nothing. If the resolver
+	// is started it is stopped first
+	Close(ctx context.Context) error
+
+	// Definitions returns a description of each definition known to
+	// the resolver, sorted by type and name
+	Definitions() []*DefInfo
+
 	// HttpHandler creates a new http request handler from a fn containing
 	// dependencies. The ResponseWriter and *Request are supplied as
 	// dependencies of the container, and will be resolved in the supplied
@@ -22,4 +43,33 @@ type IHttpResolver interface {
 	// a series of handler functions, and then calling http.Handle(pattern, injectedHandler)
 	// for each handler in the collection
 	SetDefaultServeMux(httpDefs []*HttpDef) error
+
+	// Start creates every Singleton, other than instances, in dependency
+	// order, and calls Di_Start on each one which implements IStartable
+	// once the Singletons it depends on have been started.
+	//
+	// If a Singleton cannot be created or started, the Singletons which
+	// were already started are stopped again in reverse order, and every
+	// error encountered is returned in an *ErrLifecycle. ErrStarted is
+	// returned if the resolver is already started. See Options.HookTimeout
+	Start(ctx context.Context) error
+
+	// Stop calls Di_Stop on each Singleton created by Start which
+	// implements IStoppable, in the reverse of the order they were
+	// started, so each is stopped before the Singletons it depends on.
+	// Every Singleton is stopped even if one fails, and every error
+	// encountered is returned in an *ErrLifecycle. Stop does nothing if
+	// the resolver is not started
+	Stop(ctx context.Context) error
+
+	// WriteGraph writes the dependency graph of the resolver to w in
+	// format. The graph contains a node for each definition, labelled and
+	// colored by its Lifetime, and a root node for each http handler
+	// created by the resolver. Dependencies with no definition are included
+	// as missing nodes. Optional and provider dependencies are drawn with
+	// dashed edges.
+	//
+	// The output only depends on the definitions and the handlers, so it is
+	// the same for every run of a program
+	WriteGraph(w io.Writer, format GraphFormat) error
 }
@@ -0,0 +1,21 @@
+package di
+
+import "context"
+
+// IStartable is an interface a Singleton can implement if it would like a
+// callback executed when the resolver which created it is started, such
+// as a consumer or a scheduler. See IHttpResolver.Start
+type IStartable interface {
+	// Di_Start is called when the resolver is started, after every
+	// Singleton the implementing object depends on has been started
+	Di_Start(ctx context.Context) error
+}
+
+// IStoppable is an interface a Singleton can implement if it would like a
+// callback executed when the resolver which created it is stopped. See
+// IHttpResolver.Stop
+type IStoppable interface {
+	// Di_Stop is called when the resolver is stopped, before any Singleton
+	// the implementing object depends on is stopped
+	Di_Stop(ctx context.Context) error
+}
@@ -0,0 +1,42 @@
+package di
+
+import "reflect"
+
+// inType is typeof(In)
+var inType = reflect.TypeOf(In{})
+
+// In is a marker which can be embedded in a struct to turn the struct
+// into a parameter object. When a constructor or func passed to one of the
+// resolver funcs has a parameter object as a parameter, each exported field
+// of the struct is injected as if it were a parameter of its own:
+//
+//	type StoreParams struct {
+//		di.In
+//
+//		Primary DB `di:"name=primary"`
+//		Replica DB `di:"name=replica"`
+//		Cache   Cache `di:"optional"`
+//	}
+//
+//	func NewStore(params StoreParams) Store { ... }
+//
+// Fields are tagged with the di key, using the same options as Params.
+// Unexported fields are left as their zero value
+type In struct{}
+
+// isParamObject returns true if rtype is a struct which embeds In
+func isParamObject(rtype reflect.Type) bool {
+	if rtype.Kind() != reflect.Struct {
+		return false
+	}
+
+	for index := 0; index < rtype.NumField(); index += 1 {
+		field := rtype.Field(index)
+
+		if field.Anonymous && field.Type == inType {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,142 @@
+package di
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+type paramObject struct {
+	In
+
+	A1      A `di:"name=one"`
+	A2      A `di:"name=two"`
+	Missing E `di:"optional"`
+	ignored A
+}
+
+type paramObjectB struct {
+	Params paramObject
+}
+
+func (pob *paramObjectB) B() (int, int) { return pob.Params.A1.A(), pob.Params.A2.A() }
+
+func TestIn(t *testing.T) {
+	newA := func(a int) func() A {
+		return func() A { return &aImpl{a} }
+	}
+	newB := func(params paramObject) B { return &paramObjectB{params} }
+
+	resolver, err := resolverChildNew([]*Def{
+		{Named("one", newA(1)), Singleton},
+		{Named("two", newA(2)), Singleton},
+		{newB, PerDependency},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Constructor", func(t *testing.T) {
+		var b B
+		resolveErr := resolver.Resolve(&b)
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		params := b.(*paramObjectB).Params
+		if params.A1.A() != 1 || params.A2.A() != 2 || params.Missing != nil || params.ignored != nil {
+			t.Fatal(params)
+		}
+	})
+	t.Run("HttpHandler", func(t *testing.T) {
+		var params paramObject
+		handler, err := resolver.HttpHandler(func(p paramObject, w http.ResponseWriter) { params = p })
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		handler(new(TestResponseWriter), new(http.Request))
+		if params.A1.A() != 1 || params.A2.A() != 2 {
+			t.Fatal(params)
+		}
+	})
+	t.Run("Curry", func(t *testing.T) {
+		ifn, resolveErr := resolver.Curry(func(i int, p paramObject) int { return i + p.A2.A() })
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if val := ifn.(func(int) int)(1); val != 3 {
+			t.Fatal(val)
+		}
+	})
+	t.Run("Optional_ErrNotIgnored", func(t *testing.T) {
+		constructorErr := errors.New("constructor err")
+		resolver, err := resolverChildNew([]*Def{
+			{Named("one", newA(1)), Singleton},
+			{Named("two", newA(2)), Singleton},
+			{func() (E, error) { return nil, constructorErr }, Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resolveErr := resolver.Invoke(func(paramObject) {})
+		if resolveErr == nil || resolveErr.Err != constructorErr {
+			t.Fatal(resolveErr)
+		}
+	})
+	t.Run("DependencyChain", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Named("one", newA(1)), Singleton},
+			{newB, PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr == nil {
+			t.Fatal("expecting missing definition err")
+		}
+
+		chain := resolveErr.DependencyChain
+		if len(chain) != 2 || chain[0] != bType || chain[1] != paramObjectType {
+			t.Fatal(chain)
+		}
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{
+			{Named("one", func(B) A { return nil }), Singleton},
+			{Named("two", newA(2)), Singleton},
+			{newB, PerDependency},
+		})
+
+		if err == nil {
+			t.Fatal("expecting circular dependency err")
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		type invalidTag struct {
+			In
+			A A `di:"unknown"`
+		}
+
+		_, err := resolverChildNew([]*Def{{func(invalidTag) B { return nil }, Singleton}})
+		if err == nil {
+			t.Fatal("expecting invalid tag err")
+		}
+
+		_, err = resolverChildNew([]*Def{{Params(newB, "name=one"), Singleton}})
+		if err == nil {
+			t.Fatal("expecting named parameter object err")
+		}
+	})
+}
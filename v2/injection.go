@@ -0,0 +1,154 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// dependency is a single value which is injected into a func, either as
+// one of its parameters or as a field of one of its parameter objects
+type dependency struct {
+	// Captive indicates a Singleton may capture the dependency, even if
+	// it has a shorter Lifetime. See Params
+	Captive bool
+
+	// Key is the key of the definition to inject
+	Key depKey
+
+	// Node is the node to inject, if the dependency must be resolved from
+	// a specific node instead of from whichever node is defined for Key,
+	// such as the value being decorated by a decorator
+	Node *depNode
+
+	// Optional indicates the zero value of the type should be injected if
+	// there is no definition for Key
+	Optional bool
+
+	// Provider is the type of the provider func to inject instead of the
+	// value of Key, if the dependency is a provider. nil otherwise
+	Provider reflect.Type
+
+	// Via is the type of the parameter object the dependency is a field
+	// of. nil if the dependency is a parameter of the func
+	Via reflect.Type
+}
+
+// injectedParam describes how to build the value of a single parameter
+// of a func from its dependencies
+type injectedParam struct {
+	// Deps are the dependencies of the parameter. A parameter which is
+	// not a parameter object has exactly one dependency
+	Deps []*dependency
+
+	// Fields are the indexes of the struct fields each dependency is set
+	// on, if the parameter is a parameter object. nil otherwise
+	Fields []int
+
+	// Type is the type of the parameter
+	Type reflect.Type
+}
+
+// Value returns the value of the parameter from the values of
+// its dependencies
+func (ip *injectedParam) Value(values []reflect.Value) reflect.Value {
+	if ip.Fields == nil {
+		return values[0]
+	}
+
+	value := reflect.New(ip.Type).Elem()
+	for index, field := range ip.Fields {
+		value.Field(field).Set(values[index])
+	}
+
+	return value
+}
+
+// injection describes how to inject every parameter of a func
+type injection struct {
+	// Deps are the dependencies of every parameter of the func, in order
+	Deps []*dependency
+
+	// Params describe how to build each parameter of the func
+	Params []*injectedParam
+}
+
+// newInjection returns a new injection for the parameters of an annotated
+// func. An error is returned if one of the parameter object fields has an
+// invalid tag
+func newInjection(af *annotatedFn) (*injection, error) {
+	fnType := af.Fn.Type()
+	numIn := fnType.NumIn()
+	inj := &injection{
+		Deps:   make([]*dependency, 0, numIn),
+		Params: make([]*injectedParam, numIn),
+	}
+
+	for index := range inj.Params {
+		inType := fnType.In(index)
+		tag := new(paramTag)
+
+		if index < len(af.Params) && af.Params[index] != nil {
+			tag = af.Params[index]
+		}
+
+		param := &injectedParam{Type: inType}
+
+		if isParamObject(inType) {
+			if tag.Name != "" {
+				return nil, fmt.Errorf("di: parameter object %v cannot be named: %v", inType, tag.Name)
+			}
+
+			err := param.addFields(inType)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			param.Deps = []*dependency{tag.dependency(inType, nil)}
+		}
+
+		inj.Params[index] = param
+		inj.Deps = append(inj.Deps, param.Deps...)
+	}
+
+	return inj, nil
+}
+
+// addFields adds a dependency to the parameter for each exported field
+// of the parameter object objType
+func (ip *injectedParam) addFields(objType reflect.Type) error {
+	ip.Deps = make([]*dependency, 0, objType.NumField())
+	ip.Fields = make([]int, 0, objType.NumField())
+
+	for index := 0; index < objType.NumField(); index += 1 {
+		field := objType.Field(index)
+
+		if field.PkgPath != "" || (field.Anonymous && field.Type == inType) {
+			continue
+		}
+
+		tag, err := parseParamTag(field.Tag.Get("di"))
+		if err != nil {
+			return fmt.Errorf("di: field %v of %v: %v", field.Name, objType, err)
+		}
+
+		ip.Deps = append(ip.Deps, tag.dependency(field.Type, objType))
+		ip.Fields = append(ip.Fields, index)
+	}
+
+	return nil
+}
+
+// Args returns the parameters to call the func with from the values of
+// the dependencies of the func
+func (inj *injection) Args(values []reflect.Value) []reflect.Value {
+	args := make([]reflect.Value, len(inj.Params))
+	start := 0
+
+	for index, param := range inj.Params {
+		end := start + len(param.Deps)
+		args[index] = param.Value(values[start:end])
+		start = end
+	}
+
+	return args
+}
@@ -0,0 +1,93 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Instance defines a value which has already been built, such as a
+// parsed configuration or a test fake, as a dependency of its own type.
+// Use the result as the Constructor of a Def with a Singleton Lifetime:
+//
+//	defs := []*di.Def{
+//		{di.Instance(config), di.Singleton},
+//		{di.Instance(&http.Client{Timeout: time.Second}), di.Singleton},
+//	}
+//
+// The value is cached by the resolver as if it were a Singleton which has
+// already been resolved. The same value may be defined more than once,
+// but a different value of the same type is a duplicate definition. See
+// InstanceAs to define the value as an interface it implements
+func Instance(value interface{}) interface{} {
+	if value == nil {
+		return &annotatedFn{Err: errors.New("di: Instance: value cannot be nil, use InstanceAs")}
+	}
+
+	return newInstance(reflect.ValueOf(value))
+}
+
+// InstanceAs is the same as Instance, except that value is defined as the
+// type ptrToType points to, which is usually an interface:
+//
+//	def := &di.Def{di.InstanceAs((*Store)(nil), fakeStore), di.Singleton}
+func InstanceAs(ptrToType interface{}, value interface{}) interface{} {
+	ptrType := reflect.TypeOf(ptrToType)
+	if ptrType == nil || ptrType.Kind() != reflect.Ptr {
+		return &annotatedFn{Err: fmt.Errorf("di: InstanceAs: ptrToType must be a pointer type: %v", ptrType)}
+	}
+
+	rtype := ptrType.Elem()
+	instance := reflect.New(rtype).Elem()
+
+	if value != nil {
+		rvalue := reflect.ValueOf(value)
+
+		if rvalue.Type().AssignableTo(rtype) == false {
+			return &annotatedFn{Err: fmt.Errorf("di: InstanceAs: value of type %v is not assignable to %v", rvalue.Type(), rtype)}
+		}
+
+		instance.Set(rvalue)
+	}
+
+	return newInstance(instance)
+}
+
+// newInstance returns a new *annotatedFn which returns instance
+func newInstance(instance reflect.Value) *annotatedFn {
+	fnType := reflect.FuncOf([]reflect.Type{}, []reflect.Type{instance.Type()}, false)
+	fn := reflect.MakeFunc(fnType, func([]reflect.Value) []reflect.Value {
+		return []reflect.Value{instance}
+	})
+
+	return &annotatedFn{
+		Fn:       fn,
+		Instance: instance,
+	}
+}
+
+// sameInstance returns true if both values are the exact same value. Values
+// which reference other values, such as pointers, maps and slices, are
+// compared by identity
+func sameInstance(v1, v2 reflect.Value) bool {
+	if v1.Type() != v2.Type() {
+		return false
+	}
+
+	switch v1.Kind() {
+	case reflect.Interface:
+		if v1.IsNil() || v2.IsNil() {
+			return v1.IsNil() && v2.IsNil()
+		}
+
+		return sameInstance(v1.Elem(), v2.Elem())
+	case reflect.Chan, reflect.Func, reflect.Map, reflect.Ptr, reflect.Slice, reflect.UnsafePointer:
+		return v1.Pointer() == v2.Pointer() && (v1.Kind() != reflect.Slice || v1.Len() == v2.Len())
+	}
+
+	if v1.Type().Comparable() == false {
+		return false
+	}
+
+	return v1.Interface() == v2.Interface()
+}
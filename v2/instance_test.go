@@ -0,0 +1,113 @@
+package di
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestInstance(t *testing.T) {
+	config := &concreteConfig{8080}
+	a := &aImpl{42}
+
+	t.Run("Resolve", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Instance(config), Singleton},
+			{InstanceAs((*A)(nil), a), Singleton},
+			{NewB, PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var config2 *concreteConfig
+		resolveErr := resolver.Resolve(&config2)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if config2 != config {
+			t.Fatal(config2, config)
+		}
+
+		var b B
+		resolveErr = resolver.Resolve(&b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		a1, a2 := b.B()
+		if a1 != 42 || a2 != 42 {
+			t.Fatal(a1, a2)
+		}
+	})
+	t.Run("NotClosed", func(t *testing.T) {
+		closer := new(HttpCloser)
+		resolver, err := resolverChildNew([]*Def{{InstanceAs((*A)(nil), closer), Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		handler, err := resolver.HttpHandler(func(a A) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		handler(new(TestResponseWriter), new(http.Request))
+		if closer.isClosed {
+			t.Fatal("an instance should not be closed at the end of an http request")
+		}
+	})
+	t.Run("Duplicate", func(t *testing.T) {
+		t.Run("SameInstance_NoErr", func(t *testing.T) {
+			_, err := resolverChildNew([]*Def{
+				{Instance(config), Singleton}, {Instance(config), Singleton},
+				{Instance(4), Singleton}, {Instance(4), Singleton},
+				{InstanceAs((*A)(nil), a), Singleton}, {InstanceAs((*A)(nil), a), Singleton},
+			})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		})
+		t.Run("DifferentInstance", func(t *testing.T) {
+			_, err := resolverChildNew([]*Def{
+				{Instance(config), Singleton}, {Instance(&concreteConfig{8080}), Singleton},
+			})
+
+			if err == nil {
+				t.Fatal("expecting duplicate definition err")
+			}
+		})
+		t.Run("Constructor", func(t *testing.T) {
+			_, err := resolverChildNew([]*Def{
+				{InstanceAs((*A)(nil), a), Singleton}, {NewA, Singleton},
+			})
+
+			if err == nil {
+				t.Fatal("expecting duplicate definition err")
+			}
+		})
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		invalid := []interface{}{
+			Instance(nil),
+			InstanceAs(nil, a),
+			InstanceAs((*B)(nil), a),
+		}
+
+		for _, constructor := range invalid {
+			_, err := resolverChildNew([]*Def{{constructor, Singleton}})
+
+			if err == nil {
+				t.Fatal("expecting invalid instance err")
+			}
+		}
+
+		_, err := resolverChildNew([]*Def{{Instance(config), PerResolve}})
+		if err == nil {
+			t.Fatal("expecting instance lifetime err")
+		}
+	})
+}
@@ -0,0 +1,363 @@
+package static
+
+import (
+	"errors"
+	"fmt"
+	"go/types"
+	"sort"
+	"strings"
+
+	"github.com/clavoie/di/v2"
+)
+
+// Graph is the dependency graph of a list of definitions
+type Graph struct {
+	// Nodes are the definitions with a func constructor, in the order
+	// they were defined. Duplicate definitions are only included once
+	Nodes []*Node
+
+	// Skipped are the definitions whose constructor is not a func, such
+	// as an annotated constructor, and which are not in the graph
+	Skipped []*Def
+
+	// nodes are the nodes of the graph by key
+	nodes map[string]*Node
+}
+
+// Node is a definition in a graph
+type Node struct {
+	// Def is the definition of the node
+	Def *Def
+
+	// Deps are the dependencies of the constructor of the definition, one
+	// for each parameter
+	Deps []*Dep
+
+	// HasErr is true if the constructor also returns an error
+	HasErr bool
+
+	// Key identifies the type of the node, see Key
+	Key string
+
+	// Type is the type the definition defines
+	Type types.Type
+
+	// Unsupported describes why the constructor cannot be checked
+	// statically, if it cannot be
+	Unsupported string
+}
+
+// Dep is a dependency of a node or of an http handler
+type Dep struct {
+	// Key identifies Type, see Key
+	Key string
+
+	// Provider is true if the dependency is a func() (Type, error)
+	Provider bool
+
+	// Type is the type of the dependency. For a provider this is the type
+	// it provides
+	Type types.Type
+}
+
+// NewGraph returns the graph of defs, and an error at the position of each
+// definition which would be rejected by NewResolver
+func NewGraph(pkg *Package, defs []*Def) (*Graph, []*ErrAt) {
+	g := &Graph{nodes: make(map[string]*Node)}
+	errs := make([]*ErrAt, 0)
+
+	for _, def := range defs {
+		if def.Signature == nil {
+			g.Skipped = append(g.Skipped, def)
+			continue
+		}
+
+		node, err := g.add(def)
+		if err != nil {
+			errs = append(errs, &ErrAt{err, pkg.Position(def.Lit.Pos())})
+		}
+
+		if node != nil {
+			g.Nodes = append(g.Nodes, node)
+			g.nodes[node.Key] = node
+		}
+	}
+
+	return g, errs
+}
+
+// Node returns the node of key, or nil if it has no definition
+func (g *Graph) Node(key string) *Node {
+	return g.nodes[key]
+}
+
+// add returns the node of def, or nil if def is a duplicate. An error is
+// returned if def cannot be defined
+func (g *Graph) add(def *Def) (*Node, error) {
+	sig := def.Signature
+	results := sig.Results()
+
+	if results.Len() == 0 || results.Len() > 2 {
+		return nil, errors.New("di: constructor can return exactly 1 or 2 values")
+	}
+
+	node := &Node{
+		Def:    def,
+		HasErr: results.Len() == 2,
+		Key:    Key(results.At(0).Type()),
+		Type:   results.At(0).Type(),
+	}
+
+	if ImplementsError(node.Type) {
+		return nil, fmt.Errorf("di: return value 1 cannot be an error: %v", Name(node.Type))
+	}
+
+	if IsBuiltin(node.Type) {
+		return nil, fmt.Errorf("di: return value 1 is supplied by the resolver and cannot be defined: %v", Name(node.Type))
+	}
+
+	if _, isProvider := ProviderElem(node.Type); isProvider {
+		return nil, fmt.Errorf("di: return value 1 is a provider type and cannot be defined: %v", Name(node.Type))
+	}
+
+	if node.HasErr && ImplementsError(results.At(1).Type()) == false {
+		return nil, fmt.Errorf("di: return value 2, if provided, must be an error: %v", Name(results.At(1).Type()))
+	}
+
+	if def.HasLifetime && isLifetime(def.Lifetime) == false {
+		return nil, fmt.Errorf("di: unknown lifetime: %v", def.Lifetime)
+	}
+
+	if existing, hasExisting := g.nodes[node.Key]; hasExisting {
+		sameConstructor := def.Func != nil && existing.Def.Func == def.Func
+
+		switch {
+		case sameConstructor && existing.Def.Lifetime == def.Lifetime:
+			return nil, nil
+		case sameConstructor == false:
+			return nil, fmt.Errorf("di: a dependency for %v already exists with a different constructor", Name(node.Type))
+		default:
+			return nil, fmt.Errorf("di: a dependency for %v already exists with a different lifetime: %v, %v", Name(node.Type), existing.Def.Lifetime, def.Lifetime)
+		}
+	}
+
+	switch {
+	case def.HasLifetime == false:
+		node.Unsupported = "the lifetime is not a constant"
+	case IsResultObject(node.Type):
+		node.Unsupported = "the constructor returns a result object"
+	default:
+		node.Deps, node.Unsupported = newDeps(sig)
+	}
+
+	return node, nil
+}
+
+// NewHandlerDeps returns the dependencies of the http handler sig, and a
+// description of why they cannot be checked statically, if they cannot be
+func NewHandlerDeps(sig *types.Signature) ([]*Dep, string) {
+	return newDeps(sig)
+}
+
+// newDeps returns a dependency for each parameter of sig
+func newDeps(sig *types.Signature) ([]*Dep, string) {
+	if sig.Variadic() {
+		return nil, "the func is variadic"
+	}
+
+	params := sig.Params()
+	deps := make([]*Dep, params.Len())
+
+	for index := range deps {
+		paramType := params.At(index).Type()
+
+		if isMarked(paramType, "In") {
+			return nil, "the func has a parameter object"
+		}
+
+		dep := &Dep{Type: paramType}
+		if elem, isProvider := ProviderElem(paramType); isProvider {
+			dep.Provider = true
+			dep.Type = elem
+		}
+
+		dep.Key = Key(dep.Type)
+		deps[index] = dep
+	}
+
+	return deps, ""
+}
+
+// Missing returns the dependencies of deps which are not supplied by the
+// resolver and have no definition in the graph
+func (g *Graph) Missing(deps []*Dep) []*Dep {
+	missing := make([]*Dep, 0)
+
+	for _, dep := range deps {
+		if IsBuiltin(dep.Type) || g.nodes[dep.Key] != nil {
+			continue
+		}
+
+		missing = append(missing, dep)
+	}
+
+	return missing
+}
+
+// Cycle returns the path of the first circular dependency found in the
+// graph, in the order the nodes were defined, or nil if there is none.
+// Providers are not followed
+func (g *Graph) Cycle() []*Node {
+	checked := make(map[*Node]bool)
+
+	var visit func(path []*Node) []*Node
+	visit = func(path []*Node) []*Node {
+		node := path[len(path)-1]
+
+		for _, dep := range node.Deps {
+			child := g.nodes[dep.Key]
+			if dep.Provider || child == nil || checked[child] {
+				continue
+			}
+
+			for _, seen := range path {
+				if seen == child {
+					return append(path[:len(path):len(path)], child)
+				}
+			}
+
+			cycle := visit(append(path[:len(path):len(path)], child))
+			if cycle != nil {
+				return cycle
+			}
+		}
+
+		checked[node] = true
+		return nil
+	}
+
+	for _, node := range g.Nodes {
+		if cycle := visit([]*Node{node}); cycle != nil {
+			return cycle
+		}
+	}
+
+	return nil
+}
+
+// DependencyOrder returns every node of the graph ordered so that each
+// node comes after every node it depends on, in the same order as the
+// resolver returned by di.NewResolver. Providers count as dependencies
+func (g *Graph) DependencyOrder() []*Node {
+	roots := append([]*Node{}, g.Nodes...)
+	sort.SliceStable(roots, func(i, j int) bool {
+		return Name(roots[i].Type) < Name(roots[j].Type)
+	})
+
+	nodes := make([]*Node, 0, len(g.Nodes))
+	seen := make(map[*Node]bool, len(g.Nodes))
+
+	var visit func(node *Node)
+	visit = func(node *Node) {
+		if node == nil || seen[node] {
+			return
+		}
+
+		seen[node] = true
+		for _, isProvider := range []bool{true, false} {
+			for _, dep := range node.Deps {
+				if dep.Provider == isProvider {
+					visit(g.nodes[dep.Key])
+				}
+			}
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	for _, node := range roots {
+		visit(node)
+	}
+
+	return nodes
+}
+
+// CaptivePath returns the path from the Singleton node to the first value
+// with a shorter Lifetime it would capture, following PerDependency
+// definitions and providers, or nil if there is none. An IResolver is
+// always captured
+func (g *Graph) CaptivePath(node *Node) []string {
+	if node.Def.Lifetime != di.Singleton {
+		return nil
+	}
+
+	return g.captivePath(node, []string{Name(node.Type)}, map[*Node]bool{})
+}
+
+// captivePath returns the path to the first captive value through the
+// dependencies of node. path is the path from the Singleton to node
+func (g *Graph) captivePath(node *Node, path []string, seen map[*Node]bool) []string {
+	for _, dep := range node.Deps {
+		if IsRequest(dep.Type) || IsResponseWriter(dep.Type) || IsResolver(dep.Type) {
+			return append(path[:len(path):len(path)], Name(dep.Type))
+		}
+
+		target := g.nodes[dep.Key]
+		if target == nil || seen[target] {
+			continue
+		}
+
+		seen[target] = true
+		targetPath := append(path[:len(path):len(path)], fmt.Sprintf("%v(%v)", Name(target.Type), target.Def.Lifetime))
+
+		switch target.Def.Lifetime {
+		case di.PerHttpRequest, di.PerResolve:
+			return targetPath
+		case di.PerDependency:
+			if captive := g.captivePath(target, targetPath, seen); captive != nil {
+				return captive
+			}
+		}
+	}
+
+	return nil
+}
+
+// CaptiveErr returns the error reported by NewResolver for a captive path
+func CaptiveErr(path []string) error {
+	return fmt.Errorf("di: Singleton %v captures a dependency with a shorter lifetime: %v", path[0], strings.Join(path, "->"))
+}
+
+// isLifetime returns true if l is one of the Lifetime constants
+func isLifetime(l di.Lifetime) bool {
+	switch l {
+	case di.Singleton, di.PerDependency, di.PerHttpRequest, di.PerResolve:
+		return true
+	}
+
+	return false
+}
+
+// IsResultObject returns true if t is a struct which embeds di.Out
+func IsResultObject(t types.Type) bool {
+	return isMarked(t, "Out")
+}
+
+// isMarked returns true if t is a struct which embeds di.In or di.Out,
+// named by marker
+func isMarked(t types.Type, marker string) bool {
+	st, isStruct := t.Underlying().(*types.Struct)
+	if isStruct == false {
+		return false
+	}
+
+	for index := 0; index < st.NumFields(); index += 1 {
+		field := st.Field(index)
+
+		if field.Embedded() && isDiType(field.Type(), marker) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,469 @@
+// Package static finds the dependency definitions of a package without
+// running it, by parsing and type checking its source. It is shared by the
+// digen and dilint commands
+package static
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+
+	"github.com/clavoie/di/v2"
+)
+
+// DiPath is the import path of the di package
+const DiPath = "github.com/clavoie/di/v2"
+
+// fset and imports are shared by every package loaded, so the packages
+// they import, such as di and net/http, are only type checked once
+var (
+	fset    = token.NewFileSet()
+	imports = importer.ForCompiler(fset, "source", nil)
+)
+
+// Package is a parsed and type checked package, with the definitions
+// declared in it
+type Package struct {
+	// DefVars are the package level vars of type []*di.Def initialized
+	// with a slice literal, in source order
+	DefVars []*DefVar
+
+	// Defs are all the di.Def literals in the package, in source order
+	Defs []*Def
+
+	// Dir is the directory of the package
+	Dir string
+
+	// Fset contains the positions of Files
+	Fset *token.FileSet
+
+	// Files are the parsed files of the package
+	Files []*ast.File
+
+	// HttpDefs are all the di.HttpDef literals in the package, in source
+	// order
+	HttpDefs []*HttpDef
+
+	// Info contains the types of the expressions in Files
+	Info *types.Info
+
+	// Types is the type checked package
+	Types *types.Package
+}
+
+// DefVar is a package level var of type []*di.Def
+type DefVar struct {
+	// Defs are the definitions in the slice literal of the var
+	Defs []*Def
+
+	// Name is the name of the var
+	Name string
+}
+
+// Def is a di.Def literal
+type Def struct {
+	// Constructor is the expression of the Constructor field, nil if the
+	// field is not set
+	Constructor ast.Expr
+
+	// Func is the package level func Constructor refers to, nil if
+	// Constructor is any other expression, such as an annotated
+	// constructor or a func literal
+	Func *types.Func
+
+	// HasLifetime is true if the Lifetime field is a constant expression
+	HasLifetime bool
+
+	// Lifetime is the value of the Lifetime field
+	Lifetime di.Lifetime
+
+	// LifetimeExpr is the expression of the Lifetime field, nil if the
+	// field is not set
+	LifetimeExpr ast.Expr
+
+	// Lit is the composite literal of the definition
+	Lit *ast.CompositeLit
+
+	// Signature is the type of Constructor if it is a func, otherwise nil
+	Signature *types.Signature
+}
+
+// HttpDef is a di.HttpDef literal
+type HttpDef struct {
+	// Handler is the expression of the Handler field, nil if the field
+	// is not set
+	Handler ast.Expr
+
+	// Lit is the composite literal of the http definition
+	Lit *ast.CompositeLit
+
+	// Pattern is the value of the Pattern field if it is a constant
+	Pattern string
+
+	// Signature is the type of Handler if it is a func, otherwise nil
+	Signature *types.Signature
+}
+
+// Load parses and type checks the package in dir, and finds the
+// definitions declared in it. Files named in skip are left out. A type
+// error is returned unless ignore returns true for it, ignore may be nil.
+// Load is not safe for concurrent use
+func Load(dir string, skip []string, ignore func(types.Error) bool) (*Package, error) {
+	buildPkg, err := build.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	skipped := make(map[string]bool, len(skip))
+	for _, name := range skip {
+		skipped[name] = true
+	}
+
+	pkg := &Package{
+		Dir:  dir,
+		Fset: fset,
+		Info: &types.Info{
+			Defs:  make(map[*ast.Ident]types.Object),
+			Types: make(map[ast.Expr]types.TypeAndValue),
+			Uses:  make(map[*ast.Ident]types.Object),
+		},
+	}
+
+	for _, name := range buildPkg.GoFiles {
+		if skipped[name] {
+			continue
+		}
+
+		file, err := parser.ParseFile(pkg.Fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		pkg.Files = append(pkg.Files, file)
+	}
+
+	var typeErr error
+	config := &types.Config{
+		Importer: imports,
+		Error: func(err error) {
+			if tErr, isTypeErr := err.(types.Error); isTypeErr && ignore != nil && ignore(tErr) {
+				return
+			}
+
+			if typeErr == nil {
+				typeErr = err
+			}
+		},
+	}
+
+	pkg.Types, _ = config.Check(buildPkg.ImportPath, pkg.Fset, pkg.Files, pkg.Info)
+	if typeErr != nil {
+		return nil, typeErr
+	}
+
+	for _, file := range pkg.Files {
+		pkg.findDefs(file)
+	}
+
+	return pkg, nil
+}
+
+// Position returns the file:line:column of pos
+func (p *Package) Position(pos token.Pos) token.Position {
+	return p.Fset.Position(pos)
+}
+
+// findDefs adds the definitions and def vars declared in file
+func (p *Package) findDefs(file *ast.File) {
+	defs := make(map[*ast.CompositeLit]*Def)
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		lit, isLit := n.(*ast.CompositeLit)
+		if isLit == false {
+			return true
+		}
+
+		litType := p.Info.TypeOf(lit)
+		if ptr, isPtr := litType.(*types.Pointer); isPtr {
+			// the &T of an element of a []*T literal may be elided
+			litType = ptr.Elem()
+		}
+
+		switch {
+		case isDiType(litType, "Def"):
+			def := p.newDef(lit)
+			defs[lit] = def
+			p.Defs = append(p.Defs, def)
+		case isDiType(litType, "HttpDef"):
+			p.HttpDefs = append(p.HttpDefs, p.newHttpDef(lit))
+		}
+
+		return true
+	})
+
+	for _, decl := range file.Decls {
+		genDecl, isGenDecl := decl.(*ast.GenDecl)
+		if isGenDecl == false || genDecl.Tok != token.VAR {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+
+			for index, name := range valueSpec.Names {
+				if index >= len(valueSpec.Values) || IsDefSlice(p.Info.TypeOf(name)) == false {
+					continue
+				}
+
+				lit, isLit := valueSpec.Values[index].(*ast.CompositeLit)
+				if isLit == false {
+					continue
+				}
+
+				defVar := &DefVar{Name: name.Name}
+				for _, elt := range lit.Elts {
+					if def, hasDef := defs[elemLit(elt)]; hasDef {
+						defVar.Defs = append(defVar.Defs, def)
+					}
+				}
+
+				p.DefVars = append(p.DefVars, defVar)
+			}
+		}
+	}
+}
+
+// newDef returns the definition of a di.Def literal
+func (p *Package) newDef(lit *ast.CompositeLit) *Def {
+	def := &Def{Lit: lit}
+	fields := litFields(lit, "Constructor", "Lifetime")
+	def.Constructor, def.LifetimeExpr = fields["Constructor"], fields["Lifetime"]
+
+	if def.Constructor != nil {
+		def.Func = p.funcOf(def.Constructor)
+		def.Signature, _ = p.Info.TypeOf(def.Constructor).(*types.Signature)
+	}
+
+	if def.LifetimeExpr == nil {
+		// the zero value of Lifetime
+		def.HasLifetime = true
+		return def
+	}
+
+	value := p.Info.Types[def.LifetimeExpr].Value
+	if value != nil && value.Kind() == constant.Int {
+		lifetime, isExact := constant.Int64Val(value)
+		def.HasLifetime = isExact
+		def.Lifetime = di.Lifetime(lifetime)
+	}
+
+	return def
+}
+
+// newHttpDef returns the http definition of a di.HttpDef literal
+func (p *Package) newHttpDef(lit *ast.CompositeLit) *HttpDef {
+	httpDef := &HttpDef{Lit: lit}
+	fields := litFields(lit, "Handler", "Pattern")
+	httpDef.Handler = fields["Handler"]
+
+	if httpDef.Handler != nil {
+		httpDef.Signature, _ = p.Info.TypeOf(httpDef.Handler).(*types.Signature)
+	}
+
+	if pattern := fields["Pattern"]; pattern != nil {
+		value := p.Info.Types[pattern].Value
+		if value != nil && value.Kind() == constant.String {
+			httpDef.Pattern = constant.StringVal(value)
+		}
+	}
+
+	return httpDef
+}
+
+// funcOf returns the package level func expr refers to, or nil if expr
+// is not the name of a func
+func (p *Package) funcOf(expr ast.Expr) *types.Func {
+	var ident *ast.Ident
+
+	for {
+		paren, isParen := expr.(*ast.ParenExpr)
+		if isParen == false {
+			break
+		}
+
+		expr = paren.X
+	}
+
+	switch typedExpr := expr.(type) {
+	case *ast.Ident:
+		ident = typedExpr
+	case *ast.SelectorExpr:
+		ident = typedExpr.Sel
+	default:
+		return nil
+	}
+
+	fn, isFunc := p.Info.Uses[ident].(*types.Func)
+	if isFunc == false || fn.Type().(*types.Signature).Recv() != nil {
+		return nil
+	}
+
+	if fn.Type().(*types.Signature).TypeParams() != nil {
+		return nil
+	}
+
+	return fn
+}
+
+// litFields returns the values of the fields of a struct literal, by
+// name. names are the names of the fields of the struct in order
+func litFields(lit *ast.CompositeLit, names ...string) map[string]ast.Expr {
+	fields := make(map[string]ast.Expr, len(names))
+
+	for index, elt := range lit.Elts {
+		if kv, isKeyValue := elt.(*ast.KeyValueExpr); isKeyValue {
+			if key, isIdent := kv.Key.(*ast.Ident); isIdent {
+				fields[key.Name] = kv.Value
+			}
+
+			continue
+		}
+
+		if index < len(names) {
+			fields[names[index]] = elt
+		}
+	}
+
+	return fields
+}
+
+// elemLit returns the composite literal of an element of a []*di.Def
+// literal, or nil if it is not a literal
+func elemLit(elt ast.Expr) *ast.CompositeLit {
+	if unary, isUnary := elt.(*ast.UnaryExpr); isUnary && unary.Op == token.AND {
+		elt = unary.X
+	}
+
+	lit, _ := elt.(*ast.CompositeLit)
+	return lit
+}
+
+// isDiType returns true if t is the named type name of the di package
+func isDiType(t types.Type, name string) bool {
+	named, isNamed := t.(*types.Named)
+	if isNamed == false {
+		return false
+	}
+
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == DiPath && obj.Name() == name
+}
+
+// IsDefSlice returns true if t is []*di.Def
+func IsDefSlice(t types.Type) bool {
+	slice, isSlice := t.(*types.Slice)
+	if isSlice == false {
+		return false
+	}
+
+	ptr, isPtr := slice.Elem().(*types.Pointer)
+	return isPtr && isDiType(ptr.Elem(), "Def")
+}
+
+// Key returns a string which identifies the type t, with the full import
+// path of each package it refers to
+func Key(t types.Type) string {
+	return types.TypeString(t, nil)
+}
+
+// Name returns the name of the type t as it is printed by the reflect
+// package, with the name of each package it refers to
+func Name(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		return pkg.Name()
+	})
+}
+
+// IsBuiltin returns true if t is supplied by the resolver instead of by a
+// definition
+func IsBuiltin(t types.Type) bool {
+	return IsResolver(t) || IsRequest(t) || IsResponseWriter(t)
+}
+
+// IsResolver returns true if t is di.IResolver
+func IsResolver(t types.Type) bool {
+	return isDiType(t, "IResolver")
+}
+
+// IsRequest returns true if t is *http.Request
+func IsRequest(t types.Type) bool {
+	return Key(t) == "*net/http.Request"
+}
+
+// IsResponseWriter returns true if t is http.ResponseWriter
+func IsResponseWriter(t types.Type) bool {
+	return Key(t) == "net/http.ResponseWriter"
+}
+
+// IsError returns true if t is the error interface
+func IsError(t types.Type) bool {
+	return types.Identical(t, types.Universe.Lookup("error").Type())
+}
+
+// ProviderElem returns T if t is a provider func() (T, error)
+func ProviderElem(t types.Type) (types.Type, bool) {
+	sig, isSig := t.(*types.Signature)
+	if isSig == false || sig.Params().Len() != 0 || sig.Results().Len() != 2 || sig.Variadic() {
+		return nil, false
+	}
+
+	if IsError(sig.Results().At(1).Type()) == false {
+		return nil, false
+	}
+
+	return sig.Results().At(0).Type(), true
+}
+
+// ImplementsError returns true if t implements the error interface
+func ImplementsError(t types.Type) bool {
+	errIface := types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+	return types.Implements(t, errIface)
+}
+
+// ErrAt is an error at a position of the source of a package
+type ErrAt struct {
+	// Err is the error encountered
+	Err error
+
+	// Position is the position of the source which caused the error
+	Position token.Position
+}
+
+// Error returns the position followed by the error
+func (ea *ErrAt) Error() string {
+	return fmt.Sprintf("%v: %v", ea.Position, ea.Err)
+}
+
+// SortErrs sorts errs by position
+func SortErrs(errs []*ErrAt) {
+	sort.SliceStable(errs, func(i, j int) bool {
+		pi, pj := errs[i].Position, errs[j].Position
+
+		if pi.Filename != pj.Filename {
+			return pi.Filename < pj.Filename
+		}
+
+		if pi.Line != pj.Line {
+			return pi.Line < pj.Line
+		}
+
+		return pi.Column < pj.Column
+	})
+}
@@ -5,7 +5,10 @@ package di
 type IResolver interface {
 	// Curry takes a func, resolves all parameters of the func which
 	// are known to the container, and returns a new func with those
-	// parameters supplied by the container.
+	// parameters supplied by the container. fn may be annotated with
+	// Params to request named definitions. A parameter of the form
+	// func() (T, error) is only injected as a provider if T is known to
+	// the container, otherwise it is left for the caller to supply.
 	//
 	// Explicitly:
 	//   func foo(i int, dep Dep) int { ... }
@@ -27,11 +30,19 @@ type IResolver interface {
 	Invoke(fn interface{}) *ErrResolve
 
 	// Resolve attempts to resolve a known dependency. The parameter
-	// must be a pointer to an interface
-	// type known to the resolver
+	// must be a pointer to a type known to the resolver. If the resolver
+	// is strict the type must be an interface
 	//
 	// Example:
 	//   var dep Dep
 	//   err := container.Resolve(&dep)
 	Resolve(ptrToIface interface{}) *ErrResolve
+
+	// ResolveNamed is the same as Resolve, except that it resolves the
+	// definition registered under name. See Named
+	//
+	// Example:
+	//   var db DB
+	//   err := container.ResolveNamed("replica", &db)
+	ResolveNamed(name string, ptrToIface interface{}) *ErrResolve
 }
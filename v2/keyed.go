@@ -0,0 +1,26 @@
+package di
+
+import "reflect"
+
+// stringType is typeof(string)
+var stringType = reflect.TypeOf("")
+
+// Keyed adds the value returned by constructor to a map of definitions of
+// the same type under key. Use the result as the Constructor of a Def:
+//
+//	defs := []*di.Def{
+//		{di.Keyed("visa", NewVisaProvider), di.Singleton},
+//		{di.Keyed("paypal", NewPaypalProvider), di.PerResolve},
+//	}
+//
+// A parameter of type map[string]T receives one value from each member of
+// the map of T, resolved according to the member's own Lifetime. Each key
+// may only be defined once per type. Maps may be combined with Named, in
+// which case the parameter must ask for the map by name, see Params
+func Keyed(key string, constructor interface{}) interface{} {
+	return annotate(constructor, func(af *annotatedFn) error {
+		af.Keyed = true
+		af.MapKey = key
+		return nil
+	})
+}
@@ -0,0 +1,123 @@
+package di
+
+import "testing"
+
+func TestKeyed(t *testing.T) {
+	newPlugin1 := func() Plugin { return &pluginImpl{1} }
+	newPlugin2 := func() Plugin { return &pluginImpl{2} }
+
+	t.Run("Map", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Keyed("one", newPlugin1), Singleton},
+			{Keyed("two", newPlugin2), PerDependency},
+			{NewPluginsByKey, PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var plugins1, plugins2 PluginsByKey
+		resolveErr := resolver.Resolve(&plugins1)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		resolveErr = resolver.Resolve(&plugins2)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		ps1, ps2 := plugins1.Plugins(), plugins2.Plugins()
+		if len(ps1) != 2 || ps1["one"].Plugin() != 1 || ps1["two"].Plugin() != 2 {
+			t.Fatal(ps1)
+		}
+
+		if ps1["one"] != ps2["one"] {
+			t.Fatal("expecting singleton member to be shared between resolves")
+		}
+
+		if ps1["two"] == ps2["two"] {
+			t.Fatal("expecting a new per dependency member for each resolve")
+		}
+	})
+	t.Run("Named", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{Keyed("one", newPlugin1), Singleton},
+			{Named("other", Keyed("two", newPlugin2)), Singleton},
+			{Params(NewPluginsByKey, "name=other"), PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var plugins PluginsByKey
+		resolveErr := resolver.Resolve(&plugins)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		ps := plugins.Plugins()
+		if len(ps) != 1 || ps["two"] == nil {
+			t.Fatal(ps)
+		}
+	})
+	t.Run("SameDefinition_NoErr", func(t *testing.T) {
+		defs := []*Def{{Keyed("one", newPlugin1), Singleton}}
+		_, err := resolverChildNew(append(defs, defs...))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("DuplicateKey", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{
+			{Keyed("one", newPlugin1), Singleton},
+			{Keyed("one", NewPluginsByKey), Singleton},
+			{Keyed("one", newPlugin2), Singleton},
+		})
+
+		if err == nil {
+			t.Fatal("expecting duplicate key err")
+		}
+	})
+	t.Run("DuplicateKey_Joined", func(t *testing.T) {
+		defs1 := newDefCollection()
+		err := defs1.Add(Keyed("one", newPlugin1), Singleton)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		defs2 := newDefCollection()
+		err = defs2.Add(Keyed("one", newPlugin2), Singleton)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = joinDefCollection(defs1, defs2).build()
+		if err == nil {
+			t.Fatal("expecting duplicate key err")
+		}
+	})
+	t.Run("GroupAndKeyed", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{{Group(Keyed("one", newPlugin1)), Singleton}})
+
+		if err == nil {
+			t.Fatal("expecting group and keyed err")
+		}
+	})
+	t.Run("Missing", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{NewPluginsByKey, PerDependency}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var plugins PluginsByKey
+		resolveErr := resolver.Resolve(&plugins)
+		if resolveErr == nil {
+			t.Fatal("expecting missing map err")
+		}
+	})
+}
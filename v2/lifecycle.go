@@ -0,0 +1,177 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrStarted is returned by Start if the resolver has already been
+// started, and has not been stopped since
+var ErrStarted = errors.New("di: the resolver is already started")
+
+// ErrLifecycle is returned by Start and Stop when some Singletons could
+// not be started or stopped.
+//
+// Implements the error interface
+type ErrLifecycle struct {
+	// Errs contains an error for each Singleton which could not be started
+	// or stopped. For Start the first error is the error which stopped the
+	// resolver from starting, followed by the errors of the Singletons
+	// which could not be stopped again
+	Errs []error
+}
+
+// Error returns an error string describing every error encountered
+func (el *ErrLifecycle) Error() string {
+	if len(el.Errs) == 1 {
+		return el.Errs[0].Error()
+	}
+
+	msgs := make([]string, len(el.Errs))
+	for index, err := range el.Errs {
+		msgs[index] = err.Error()
+	}
+
+	return fmt.Sprintf("di: %v lifecycle errors:\n\t%v", len(el.Errs), strings.Join(msgs, "\n\t"))
+}
+
+// lifecycleHook is a Singleton which implements IStartable or IStoppable
+type lifecycleHook struct {
+	// TypeName is the type name of the definition of the Singleton
+	TypeName string
+
+	// Value is the value of the Singleton
+	Value interface{}
+}
+
+func (c *resolverParent) Start(ctx context.Context) error {
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	if c.isClosed() {
+		return ErrClosed
+	}
+
+	if c.started != nil {
+		return ErrStarted
+	}
+
+	resolver := newResolverChild(c)
+	seen := make(map[interface{}]bool)
+	started := make([]*lifecycleHook, 0)
+
+	for _, node := range dependencyOrder(c.allDeps) {
+		if node.Lifetime != Singleton || node.IsGroup() || node.Annotated != nil && node.Annotated.IsInstance() {
+			continue
+		}
+
+		value, resolveErr := resolver.resolveNode(nil, nil, node)
+		if resolveErr != nil {
+			return c.rollback(ctx, started, resolveErr)
+		}
+
+		switch value.Interface().(type) {
+		case IStartable, IStoppable:
+		default:
+			continue
+		}
+
+		if value.Type().Comparable() {
+			if seen[value.Interface()] {
+				continue
+			}
+
+			seen[value.Interface()] = true
+		}
+
+		if startable, isStartable := value.Interface().(IStartable); isStartable {
+			err := runHook(ctx, c.options.HookTimeout, startable.Di_Start)
+			if err != nil {
+				return c.rollback(ctx, started, fmt.Errorf("di: starting %v: %w", node.TypeName, err))
+			}
+		}
+
+		started = append(started, &lifecycleHook{node.TypeName, value.Interface()})
+	}
+
+	c.started = started
+	return nil
+}
+
+func (c *resolverParent) Stop(ctx context.Context) error {
+	c.lifecycleLock.Lock()
+	defer c.lifecycleLock.Unlock()
+
+	errs := c.stop(ctx)
+	if len(errs) > 0 {
+		return &ErrLifecycle{errs}
+	}
+
+	return nil
+}
+
+// stop stops the started Singletons in the reverse of the order they were
+// started in, returning every error encountered. The caller must hold
+// lifecycleLock
+func (c *resolverParent) stop(ctx context.Context) []error {
+	errs := stopHooks(ctx, c.options.HookTimeout, c.started)
+	c.started = nil
+	return errs
+}
+
+// rollback stops the Singletons in started after err stopped the resolver
+// from starting, returning an *ErrLifecycle
+func (c *resolverParent) rollback(ctx context.Context, started []*lifecycleHook, err error) error {
+	errs := append([]error{err}, stopHooks(ctx, c.options.HookTimeout, started)...)
+	return &ErrLifecycle{errs}
+}
+
+// stopHooks calls Di_Stop on each of hooks which is an IStoppable, in
+// reverse order, returning every error encountered
+func stopHooks(ctx context.Context, timeout time.Duration, hooks []*lifecycleHook) []error {
+	errs := make([]error, 0)
+
+	for index := len(hooks) - 1; index >= 0; index -= 1 {
+		stoppable, isStoppable := hooks[index].Value.(IStoppable)
+		if isStoppable == false {
+			continue
+		}
+
+		err := runHook(ctx, timeout, stoppable.Di_Stop)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("di: stopping %v: %w", hooks[index].TypeName, err))
+		}
+	}
+
+	return errs
+}
+
+// runHook calls hook, returning the error of ctx instead if ctx is done,
+// or if timeout is more than 0 and hook does not return within timeout. A
+// hook which does not return in time is left running
+func runHook(ctx context.Context, timeout time.Duration, hook func(context.Context) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- hook(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
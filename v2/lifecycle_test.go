@@ -0,0 +1,230 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testHook records its name in hooks when it is started or stopped
+type testHook struct {
+	block    chan struct{}
+	hooks    *[]string
+	name     string
+	startErr error
+	stopErr  error
+}
+
+func (th *testHook) A() int        { return 0 }
+func (th *testHook) B() (int, int) { return 0, 0 }
+
+func (th *testHook) Di_Start(ctx context.Context) error {
+	if th.block != nil {
+		<-th.block
+	}
+
+	*th.hooks = append(*th.hooks, th.name+".start")
+	return th.startErr
+}
+
+func (th *testHook) Di_Stop(ctx context.Context) error {
+	*th.hooks = append(*th.hooks, th.name+".stop")
+	return th.stopErr
+}
+
+// testStopHook only records its name in hooks when it is stopped
+type testStopHook struct {
+	hooks *[]string
+	name  string
+}
+
+func (tsh *testStopHook) Di_Stop(ctx context.Context) error {
+	*tsh.hooks = append(*tsh.hooks, tsh.name+".stop")
+	return nil
+}
+
+func TestLifecycle(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Order", func(t *testing.T) {
+		hooks := []string{}
+		newC := func(D, func() (E, error)) C { return &testHook{hooks: &hooks, name: "c"} }
+		newD := func(E) D { return &testStopHook{hooks: &hooks, name: "d"} }
+		newE := func() E { return &testHook{hooks: &hooks, name: "e"} }
+		instance := &testHook{hooks: &hooks, name: "instance"}
+
+		resolver, err := resolverChildNew([]*Def{
+			{newC, Singleton},
+			{newD, Singleton},
+			{newE, Singleton},
+			{InstanceAs((*A)(nil), instance), Singleton},
+			{func() B { return &testHook{hooks: &hooks, name: "b"} }, PerDependency},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := resolver.Start(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := resolver.Start(ctx); err != ErrStarted {
+			t.Fatal("expecting the resolver to be started", err)
+		}
+
+		if err := resolver.Stop(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Join(hooks, ",") != "e.start,c.start,c.stop,d.stop,e.stop" {
+			t.Fatal("expecting Singletons in dependency order", hooks)
+		}
+
+		if err := resolver.Stop(ctx); err != nil || len(hooks) != 5 {
+			t.Fatal("expecting Stop to do nothing once stopped", err, hooks)
+		}
+
+		if err := resolver.Start(ctx); err != nil || len(hooks) != 7 {
+			t.Fatal("expecting the resolver to start again", err, hooks)
+		}
+	})
+	t.Run("Rollback", func(t *testing.T) {
+		hooks := []string{}
+		errC := errors.New("c")
+		errE := errors.New("e")
+		newC := func(D) C { return &testHook{hooks: &hooks, name: "c", startErr: errC} }
+		newD := func(E) D { return &testHook{hooks: &hooks, name: "d"} }
+		newE := func() E { return &testHook{hooks: &hooks, name: "e", stopErr: errE} }
+
+		resolver, err := resolverChildNew([]*Def{{newC, Singleton}, {newD, Singleton}, {newE, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = resolver.Start(ctx)
+		var lifecycleErr *ErrLifecycle
+		if errors.As(err, &lifecycleErr) == false || len(lifecycleErr.Errs) != 2 {
+			t.Fatal("expecting the start and rollback errors", err)
+		}
+
+		if errors.Is(lifecycleErr.Errs[0], errC) == false || errors.Is(lifecycleErr.Errs[1], errE) == false {
+			t.Fatal("unexpected errors", err)
+		}
+
+		if strings.HasPrefix(err.Error(), "di: 2 lifecycle errors:\n\tdi: starting di.C: c\n\tdi: stopping di.E: e") == false {
+			t.Fatal("unexpected error", err)
+		}
+
+		if strings.Join(hooks, ",") != "e.start,d.start,c.start,d.stop,e.stop" {
+			t.Fatal("expecting started Singletons to be stopped", hooks)
+		}
+
+		if err := resolver.Stop(ctx); err != nil || len(hooks) != 5 {
+			t.Fatal("expecting the resolver not to be started", err, hooks)
+		}
+	})
+	t.Run("ResolveErr", func(t *testing.T) {
+		hooks := []string{}
+		errD := errors.New("d")
+		newC := func(D) C { return &testHook{hooks: &hooks, name: "c"} }
+		newD := func() (D, error) { return nil, errD }
+		newE := func() E { return &testHook{hooks: &hooks, name: "e"} }
+
+		resolver, err := resolverChildNew([]*Def{{newC, Singleton}, {newD, Singleton}, {newE, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = resolver.Start(ctx)
+		var lifecycleErr *ErrLifecycle
+		if errors.As(err, &lifecycleErr) == false || len(lifecycleErr.Errs) != 1 {
+			t.Fatal("expecting the constructor error", err)
+		}
+
+		resolveErr, isResolveErr := lifecycleErr.Errs[0].(*ErrResolve)
+		if isResolveErr == false || resolveErr.Err != errD {
+			t.Fatal("expecting the constructor error", err)
+		}
+
+		if strings.Join(hooks, ",") != "" {
+			t.Fatal("expecting nothing to be started", hooks)
+		}
+	})
+	t.Run("Timeout", func(t *testing.T) {
+		hooks := []string{}
+		block := make(chan struct{})
+		defer close(block)
+
+		newC := func(E) C { return &testHook{block: block, hooks: &[]string{}, name: "c"} }
+		newE := func() E { return &testHook{hooks: &hooks, name: "e"} }
+		errFn := func(er *ErrResolve, w http.ResponseWriter, r *http.Request) {}
+
+		options := Options{HookTimeout: 10 * time.Millisecond}
+		resolver, err := NewResolverWithOptions(errFn, options, []*Def{{newC, Singleton}, {newE, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = resolver.Start(ctx)
+		var lifecycleErr *ErrLifecycle
+		if errors.As(err, &lifecycleErr) == false || errors.Is(lifecycleErr.Errs[0], context.DeadlineExceeded) == false {
+			t.Fatal("expecting the hook to time out", err)
+		}
+
+		if strings.Join(hooks, ",") != "e.start,e.stop" {
+			t.Fatal("expecting started Singletons to be stopped", hooks)
+		}
+	})
+	t.Run("Stop", func(t *testing.T) {
+		hooks := []string{}
+		errC := errors.New("c")
+		errE := errors.New("e")
+		newC := func(E) C { return &testHook{hooks: &hooks, name: "c", stopErr: errC} }
+		newE := func() E { return &testHook{hooks: &hooks, name: "e", stopErr: errE} }
+
+		resolver, err := resolverChildNew([]*Def{{newC, Singleton}, {newE, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := resolver.Start(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		err = resolver.Stop(ctx)
+		var lifecycleErr *ErrLifecycle
+		if errors.As(err, &lifecycleErr) == false || len(lifecycleErr.Errs) != 2 {
+			t.Fatal("expecting every Singleton to be stopped", err)
+		}
+
+		if strings.Join(hooks, ",") != "e.start,c.start,c.stop,e.stop" {
+			t.Fatal("unexpected hooks", hooks)
+		}
+	})
+	t.Run("Close", func(t *testing.T) {
+		hooks := []string{}
+		resolver, err := resolverChildNew([]*Def{{func() C { return &testHook{hooks: &hooks, name: "c"} }, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := resolver.Start(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := resolver.Close(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Join(hooks, ",") != "c.start,c.stop" {
+			t.Fatal("expecting Close to stop the resolver", hooks)
+		}
+
+		if err := resolver.Start(ctx); err != ErrClosed {
+			t.Fatal("expecting the resolver to be closed", err)
+		}
+	})
+}
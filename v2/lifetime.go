@@ -1,12 +1,21 @@
 package di
 
+import "fmt"
+
 // Lifetime indicates the caching policy for resolved types
 type Lifetime int
 
 const (
 	// Singleton indicates only one instance of the type
 	// should be created ever, and used for every dependency
-	// encountered going forward
+	// encountered going forward.
+	//
+	// A Singleton cannot depend on a PerHttpRequest or PerResolve
+	// type, or on the http request, either directly, through
+	// PerDependency types or through a provider, as it would keep the
+	// first value it is resolved with forever. For the same reason a
+	// Singleton cannot depend on IResolver. A dependency tagged as
+	// captive is allowed, see Params
 	Singleton Lifetime = iota
 
 	// PerDependency indicates that a new instance of the
@@ -42,6 +51,22 @@ const (
 	PerResolve
 )
 
+// String returns the name of the Lifetime
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "Singleton"
+	case PerDependency:
+		return "PerDependency"
+	case PerHttpRequest:
+		return "PerHttpRequest"
+	case PerResolve:
+		return "PerResolve"
+	}
+
+	return fmt.Sprintf("Lifetime(%d)", int(l))
+}
+
 // lifetimes is a collection of all known Lifetime values
 var lifetimes = map[Lifetime]bool{
 	Singleton:      true,
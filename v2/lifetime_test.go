@@ -2,6 +2,7 @@ package di
 
 import (
 	"net/http"
+	"strings"
 	"testing"
 )
 
@@ -91,4 +92,16 @@ func TestLifetime(t *testing.T) {
 			t.Fatal(Singleton, a2, expectedA2)
 		}
 	})
+
+	t.Run("String", func(t *testing.T) {
+		for lifetime := range lifetimes {
+			if strings.HasPrefix(lifetime.String(), "Lifetime(") {
+				t.Fatal(int(lifetime))
+			}
+		}
+
+		if Lifetime(-1).String() != "Lifetime(-1)" {
+			t.Fatal(Lifetime(-1).String())
+		}
+	})
 }
@@ -0,0 +1,236 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Module is a named collection of definitions which only makes some of its
+// definitions available outside of the module:
+//
+//	storage := &di.Module{
+//		Name:    "storage",
+//		Defs:    []*di.Def{{NewPool, di.Singleton}, {NewStore, di.Singleton}},
+//		Exports: []interface{}{(*Store)(nil)},
+//	}
+//
+//	billing := &di.Module{
+//		Name:    "billing",
+//		Defs:    []*di.Def{{NewInvoicer, di.Singleton}},
+//		Imports: []*di.Module{storage},
+//		Exports: []interface{}{(*Invoicer)(nil)},
+//	}
+//
+//	resolver, err := di.NewResolver(errFn, di.Modules(billing))
+//
+// The definitions of a module may depend on any other definition of the
+// same module, on the exported types of the modules it imports, and on
+// definitions which are not part of any module. Definitions which are not
+// part of a module, and the funcs passed to a resolver, may only depend on
+// exported types. Depending on a type which is not exported is reported
+// when the resolver is created, or when the type is resolved
+type Module struct {
+	// Name is the name of the module, which must be unique
+	Name string
+
+	// Defs are the definitions of the module
+	Defs []*Def
+
+	// Imports are the modules whose exported types may be used by the
+	// definitions of the module
+	Imports []*Module
+
+	// Exports are pointers to the types of the module which may be used
+	// outside of the module, as with Alias. Every definition of an exported
+	// type is exported, whatever its name
+	Exports []interface{}
+}
+
+// Modules returns the definitions of each module and every module it
+// imports, to be passed to NewResolver. A module imported more than once
+// is only included once
+func Modules(modules ...*Module) []*Def {
+	defs := make([]*Def, 0)
+	added := make(map[*Module]bool)
+
+	var addModule func(*Module)
+	addModule = func(m *Module) {
+		if added[m] {
+			return
+		}
+
+		added[m] = true
+		for _, imported := range m.Imports {
+			addModule(imported)
+		}
+
+		for _, def := range m.Defs {
+			defs = append(defs, &Def{inModule(m, def.Constructor), def.Lifetime})
+		}
+	}
+
+	for _, m := range modules {
+		addModule(m)
+	}
+
+	return defs
+}
+
+// inModule annotates constructor as a definition of the module m
+func inModule(m *Module, constructor interface{}) interface{} {
+	return annotate(constructor, func(af *annotatedFn) error {
+		if af.Module != nil && af.Module != m {
+			return fmt.Errorf("di: module %q: definition is already part of module %q: %v", m.Name, af.Module.Name, af)
+		}
+
+		_, err := m.exports()
+		if err != nil {
+			return err
+		}
+
+		af.Module = m
+		return nil
+	})
+}
+
+// exports returns the set of types exported by the module
+func (m *Module) exports() (map[reflect.Type]bool, error) {
+	exports := make(map[reflect.Type]bool, len(m.Exports))
+
+	for _, ptr := range m.Exports {
+		exportType, err := ptrElem("Module", ptr)
+
+		if err != nil {
+			return nil, fmt.Errorf("di: module %q: %v", m.Name, err)
+		}
+
+		exports[exportType] = true
+	}
+
+	return exports, nil
+}
+
+// imports returns true if the module imports m2
+func (m *Module) imports(m2 *Module) bool {
+	for _, imported := range m.Imports {
+		if imported == m2 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// moduleOf returns the module node is defined in, or nil if it is not
+// part of a module. Derived definitions are part of the module of their
+// parent
+func moduleOf(node *depNode) *Module {
+	if node.Annotated == nil {
+		return nil
+	}
+
+	return node.Annotated.Root().Module
+}
+
+// isExported returns true if node may be used outside of its module
+func isExported(node *depNode) bool {
+	m := moduleOf(node)
+	if m == nil {
+		return true
+	}
+
+	exports, _ := m.exports()
+	return exports[node.Type]
+}
+
+// verifyVisible returns an error if a definition in module m, named
+// typeName, may not depend on dep because dep is part of a module which
+// does not export it to m. m is nil if the definition is not part of a
+// module. The members of a group are checked individually
+func verifyVisible(m *Module, typeName string, dep *depNode) error {
+	if dep.IsGroup() {
+		for _, member := range dep.Members {
+			err := verifyVisible(m, typeName, member)
+
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	depModule := moduleOf(dep)
+	if depModule == nil || m == depModule {
+		return nil
+	}
+
+	if isExported(dep) == false {
+		return fmt.Errorf("di: %v depends on %v, which is not exported by module %q", typeName, dep.TypeName, depModule.Name)
+	}
+
+	if m != nil && m.imports(depModule) == false {
+		return fmt.Errorf("di: %v in module %q depends on %v from module %q, which it does not import", typeName, m.Name, dep.TypeName, depModule.Name)
+	}
+
+	return nil
+}
+
+// verifyRootVisible returns an error if any of deps, the dependencies of
+// a root func named typeName, is part of a module which does not export it
+func (c *resolverParent) verifyRootVisible(typeName string, deps []*dependency) error {
+	for _, dep := range deps {
+		node, hasNode := c.allDeps[dep.Key]
+		if hasNode == false {
+			continue
+		}
+
+		err := verifyVisible(nil, typeName, node)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// verifyModules returns an error if two different modules have the same
+// name, or if a definition depends on a type which is not visible to it.
+// See Module
+func verifyModules(deps map[depKey]*depNode) error {
+	names := make(map[string]*Module)
+	nodes := allNodes(deps)
+
+	for _, node := range nodes {
+		m := moduleOf(node)
+		if m == nil {
+			continue
+		}
+
+		if existing, hasName := names[m.Name]; hasName && existing != m {
+			return fmt.Errorf("di: more than one module is named %q", m.Name)
+		}
+
+		names[m.Name] = m
+	}
+
+	for _, node := range nodes {
+		for _, dependsOn := range node.DependsOn {
+			dep, hasDep := dependsOn.Node, dependsOn.Node != nil
+			if hasDep == false {
+				dep, hasDep = deps[dependsOn.Key]
+			}
+
+			if hasDep == false {
+				continue
+			}
+
+			err := verifyVisible(moduleOf(node), node.TypeName, dep)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,120 @@
+package di
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestModule(t *testing.T) {
+	errFn := func(er *ErrResolve, w http.ResponseWriter, r *http.Request) { panic(er) }
+	newA := func() A { return &aImpl{1} }
+	newB := func(a A) B { return &bImpl{a.A(), a.A()} }
+	newD := func(B) D { return new(struct{}) }
+	newE := func(A) E { return new(struct{}) }
+	storage := &Module{
+		Name:    "storage",
+		Defs:    []*Def{{newA, Singleton}, {newB, Singleton}},
+		Exports: []interface{}{(*B)(nil)},
+	}
+
+	t.Run("Exported", func(t *testing.T) {
+		billing := &Module{
+			Name:    "billing",
+			Defs:    []*Def{{newD, Singleton}},
+			Imports: []*Module{storage},
+			Exports: []interface{}{(*D)(nil)},
+		}
+
+		resolver, err := NewResolver(errFn, Modules(billing, storage), []*Def{{func(D) C { return nil }, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a1, _ := b.B(); a1 != 1 {
+			t.Fatal(a1)
+		}
+
+		var a A
+		resolveErr = resolver.Resolve(&a)
+		if resolveErr == nil || strings.Contains(resolveErr.Err.Error(), `"storage"`) == false {
+			t.Fatal(resolveErr)
+		}
+
+		infos := resolver.Definitions()
+		if infos[0].Module != "storage" || infos[2].Module != "" || infos[3].Module != "billing" {
+			t.Fatal(infos)
+		}
+	})
+	t.Run("NotExportedRoot", func(t *testing.T) {
+		resolver, err := NewResolver(errFn, Modules(storage))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resolveErr := resolver.Invoke(func(p struct {
+			In
+			A A
+		}) {
+		})
+		if resolveErr == nil || strings.Contains(resolveErr.Err.Error(), `"storage"`) == false {
+			t.Fatal("expecting the field of a parameter object to be checked", resolveErr)
+		}
+
+		var providerErr error
+		resolveErr = resolver.Invoke(func(provider func() (A, error)) {
+			_, providerErr = provider()
+		})
+		if resolveErr != nil || providerErr == nil || strings.Contains(providerErr.Error(), `"storage"`) == false {
+			t.Fatal("expecting the provider to be checked", resolveErr, providerErr)
+		}
+
+		_, err = resolver.HttpHandler(func(A) {})
+		if err == nil || strings.Contains(err.Error(), `"storage"`) == false {
+			t.Fatal("expecting the handler to be checked when it is created", err)
+		}
+
+		options := Options{Validate: true, Roots: []interface{}{func(B, A) {}}}
+		_, err = NewResolverWithOptions(errFn, options, Modules(storage))
+		if err == nil || strings.Contains(err.Error(), `"storage"`) == false {
+			t.Fatal("expecting the roots to be checked", err)
+		}
+	})
+	t.Run("NotExported", func(t *testing.T) {
+		_, err := NewResolver(errFn, Modules(storage), []*Def{{newE, Singleton}})
+
+		if err == nil || strings.Contains(err.Error(), `not exported by module "storage"`) == false {
+			t.Fatal(err)
+		}
+	})
+	t.Run("NotImported", func(t *testing.T) {
+		billing := &Module{Name: "billing", Defs: []*Def{{newD, Singleton}}}
+		_, err := NewResolver(errFn, Modules(storage, billing))
+
+		if err == nil || strings.Contains(err.Error(), "does not import") == false {
+			t.Fatal(err)
+		}
+	})
+	t.Run("DuplicateName", func(t *testing.T) {
+		other := &Module{Name: "storage", Defs: []*Def{{newD, Singleton}}}
+		_, err := NewResolver(errFn, Modules(storage, other))
+
+		if err == nil {
+			t.Fatal("expecting err for two modules with the same name")
+		}
+	})
+	t.Run("InvalidExport", func(t *testing.T) {
+		invalid := &Module{Name: "invalid", Defs: []*Def{{newA, Singleton}}, Exports: []interface{}{1}}
+		_, err := NewResolver(errFn, Modules(invalid))
+
+		if err == nil {
+			t.Fatal("expecting err for an export which is not a pointer")
+		}
+	})
+}
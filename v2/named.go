@@ -0,0 +1,20 @@
+package di
+
+// Named registers the value returned by constructor under name. Use
+// the result as the Constructor of a Def:
+//
+//	defs := []*di.Def{
+//		{di.Named("primary", NewPrimaryDB), di.Singleton},
+//		{di.Named("replica", NewReplicaDB), di.Singleton},
+//	}
+//
+// Several definitions of the same type may be registered as long as
+// each has a different name. A named definition is only injected into
+// parameters which ask for the name, see Params, or resolved by
+// IResolver.ResolveNamed
+func Named(name string, constructor interface{}) interface{} {
+	return annotate(constructor, func(af *annotatedFn) error {
+		af.Name = name
+		return nil
+	})
+}
@@ -0,0 +1,133 @@
+package di
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestNamed(t *testing.T) {
+	newA := func(a int) func() A {
+		return func() A { return &aImpl{a} }
+	}
+
+	resolver, err := resolverChildNew([]*Def{
+		{Named("one", newA(1)), Singleton},
+		{Named("two", newA(2)), Singleton},
+		{Params(NewB, "name=one", "name=two"), PerDependency},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("ResolveNamed", func(t *testing.T) {
+		var a A
+		resolveErr := resolver.ResolveNamed("two", &a)
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a.A() != 2 {
+			t.Fatal(a.A())
+		}
+	})
+	t.Run("Unnamed_Missing", func(t *testing.T) {
+		var a A
+		resolveErr := resolver.Resolve(&a)
+
+		if resolveErr == nil {
+			t.Fatal("expecting unnamed definition to be missing")
+		}
+	})
+	t.Run("Name_Missing", func(t *testing.T) {
+		var a A
+		resolveErr := resolver.ResolveNamed("three", &a)
+
+		if resolveErr == nil {
+			t.Fatal("expecting named definition to be missing")
+		}
+
+		missingErr, isMissingErr := resolveErr.Err.(*ErrDefMissing)
+		if isMissingErr == false {
+			t.Fatal(resolveErr.Err)
+		}
+
+		if missingErr.Name != "three" {
+			t.Fatal(missingErr.Name)
+		}
+	})
+	t.Run("Constructor", func(t *testing.T) {
+		var b B
+		resolveErr := resolver.Resolve(&b)
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		a1, a2 := b.B()
+		if a1 != 1 || a2 != 2 {
+			t.Fatal(a1, a2)
+		}
+	})
+	t.Run("Curry", func(t *testing.T) {
+		ifn, resolveErr := resolver.Curry(Params(func(i int, a A) int { return i + a.A() }, "", "name=two"))
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		val := ifn.(func(int) int)(1)
+		if val != 3 {
+			t.Fatal(val)
+		}
+	})
+	t.Run("Invoke", func(t *testing.T) {
+		val := 0
+		resolveErr := resolver.Invoke(Params(func(a A) { val = a.A() }, "name=one"))
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if val != 1 {
+			t.Fatal(val)
+		}
+	})
+	t.Run("HttpHandler", func(t *testing.T) {
+		val := 0
+		handler, err := resolver.HttpHandler(Params(func(w http.ResponseWriter, a A) { val = a.A() }, "", "name=two"))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		handler(new(TestResponseWriter), new(http.Request))
+		if val != 2 {
+			t.Fatal(val)
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		t.Run("NotFn", func(t *testing.T) {
+			_, err := resolverChildNew([]*Def{{Named("one", "invalid"), Singleton}})
+
+			if err == nil {
+				t.Fatal("expecting not a func err")
+			}
+		})
+		t.Run("TooManyTags", func(t *testing.T) {
+			_, err := resolverChildNew([]*Def{{Params(NewA, "name=one"), Singleton}})
+
+			if err == nil {
+				t.Fatal("expecting too many tags err")
+			}
+		})
+		t.Run("UnknownTagOption", func(t *testing.T) {
+			_, err := resolver.HttpHandler(Params(func(a A) {}, "unknown=one"))
+
+			if err == nil {
+				t.Fatal("expecting unknown tag option err")
+			}
+		})
+	})
+}
@@ -0,0 +1,109 @@
+package di
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestOptional(t *testing.T) {
+	newA := func() A { return &aImpl{1} }
+	newAErr := func() (A, error) { return nil, errors.New("newAErr") }
+	newC := func(D) C { return new(struct{}) }
+	newB := func(a A, c C) B {
+		if a == nil {
+			return &bImpl{}
+		}
+
+		return &bImpl{a.A(), 0}
+	}
+	optionalB := Params(newB, "optional", "optional")
+
+	t.Run("Missing", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{optionalB, PerDependency}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a1, _ := b.B(); a1 != 0 {
+			t.Fatal(a1)
+		}
+	})
+	t.Run("Defined", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{optionalB, PerDependency}, {newA, Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a1, _ := b.B(); a1 != 1 {
+			t.Fatal(a1)
+		}
+	})
+	t.Run("ConstructorErr", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{optionalB, PerDependency}, {newAErr, Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr == nil || resolveErr.Err.Error() != "newAErr" {
+			t.Fatal(resolveErr)
+		}
+	})
+	t.Run("DependencyMissing", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{optionalB, PerDependency}, {newC, Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr == nil {
+			t.Fatal("expecting the missing dependency of a defined optional dependency to be reported")
+		}
+
+		missingErr, isMissingErr := resolveErr.Err.(*ErrDefMissing)
+		if isMissingErr == false || missingErr.Type != reflect.TypeOf((*D)(nil)).Elem() {
+			t.Fatal(resolveErr)
+		}
+	})
+	t.Run("HttpHandler", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		isCalled := false
+		handler, err := resolver.HttpHandler(Params(func(a A, w http.ResponseWriter) {
+			isCalled = a == nil && w != nil
+		}, "optional"))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		handler(new(TestResponseWriter), new(http.Request))
+		if isCalled == false {
+			t.Fatal("expecting handler to be called with a nil A")
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package di
+
+import "time"
+
+// Options configures the behavior of a resolver. See NewResolverWithOptions
+type Options struct {
+	// Strict restricts the resolver to interface types. Constructors must
+	// return an interface, and Resolve must be passed a pointer to an
+	// interface. By default any type other than an error may be defined
+	// and resolved
+	Strict bool
+
+	// Profiles are the active profiles of the resolver. A definition with
+	// a Profile condition is only added if one of its profiles is active.
+	// See When
+	Profiles []string
+
+	// Validate checks that every dependency of every definition has a
+	// definition when the resolver is created, instead of when it is first
+	// resolved. Every dependency which has no definition is reported at
+	// once in an *ErrValidation. Optional dependencies are not checked
+	Validate bool
+
+	// Roots are funcs which will be passed to the resolver, such as http
+	// handlers or funcs passed to Invoke. If Validate is true, their
+	// dependencies are checked along with those of every definition
+	Roots []interface{}
+
+	// HookTimeout limits the time each Di_Start and Di_Stop call may take
+	// when the resolver is started or stopped. A call which takes longer
+	// fails with context.DeadlineExceeded. By default there is no limit
+	// other than the context passed to Start or Stop
+	HookTimeout time.Duration
+}
@@ -0,0 +1,144 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// outType is typeof(Out)
+var outType = reflect.TypeOf(Out{})
+
+// Out is a marker which can be embedded in a struct to turn the struct
+// into a result object. When a constructor returns a result object, each
+// exported field of the struct is defined as a dependency of its own:
+//
+//	type StoreResult struct {
+//		di.Out
+//
+//		Reader Reader
+//		Writer Writer
+//		Closer io.Closer `di:"name=store"`
+//	}
+//
+//	func NewStore(db DB) (StoreResult, error) { ... }
+//
+// The constructor is called once per Lifetime of its Def, and every field
+// is resolved from that same result. Fields are tagged with the di key,
+// using a comma separated list of options:
+//
+//	name=<name>	define the field under <name>, see Named
+//	group		add the field to a group of definitions, see Group
+//	key=<key>	add the field to a map of definitions under <key>, see Keyed
+//
+// Unexported fields are ignored
+type Out struct{}
+
+// isResultObject returns true if rtype is a struct which embeds Out
+func isResultObject(rtype reflect.Type) bool {
+	if rtype.Kind() != reflect.Struct {
+		return false
+	}
+
+	for index := 0; index < rtype.NumField(); index += 1 {
+		field := rtype.Field(index)
+
+		if field.Anonymous && field.Type == outType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resultField identifies a field of a result object
+type resultField struct {
+	// Index is the index of the field in the result object
+	Index int
+
+	// Result is the constructor of the result object
+	Result *annotatedFn
+}
+
+// newResultFields returns an annotated func for each exported field of
+// the result object returned by result. Each func takes the result object
+// as its only parameter and returns the value of its field
+func newResultFields(result *annotatedFn) ([]*annotatedFn, error) {
+	objType := result.Fn.Type().Out(0)
+	fields := make([]*annotatedFn, 0, objType.NumField())
+
+	for index := 0; index < objType.NumField(); index += 1 {
+		field := objType.Field(index)
+
+		if field.PkgPath != "" || (field.Anonymous && field.Type == outType) {
+			continue
+		}
+
+		annotated := newResultFieldFn(result, index)
+		err := annotated.parseResultTag(field.Tag.Get("di"))
+
+		if err != nil {
+			return nil, fmt.Errorf("di: field %v of %v: %v", field.Name, objType, err)
+		}
+
+		fields = append(fields, annotated)
+	}
+
+	return fields, nil
+}
+
+// newResultFieldFn returns an annotated func which returns the field
+// at index of the result object returned by result
+func newResultFieldFn(result *annotatedFn, index int) *annotatedFn {
+	objType := result.Fn.Type().Out(0)
+	fieldType := objType.Field(index).Type
+	fnType := reflect.FuncOf([]reflect.Type{objType}, []reflect.Type{fieldType}, false)
+	fn := reflect.MakeFunc(fnType, func(ins []reflect.Value) []reflect.Value {
+		return []reflect.Value{ins[0].Field(index)}
+	})
+
+	return &annotatedFn{
+		Field: &resultField{
+			Index:  index,
+			Result: result,
+		},
+		Fn:     fn,
+		Parent: result,
+	}
+}
+
+// parseResultTag sets the options of a result object field tag on the
+// annotated func, returning an error if the tag contains an unknown option
+func (af *annotatedFn) parseResultTag(tag string) error {
+	for _, option := range strings.Split(tag, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+
+		key, value := option, ""
+		if index := strings.Index(option, "="); index >= 0 {
+			key, value = option[:index], option[index+1:]
+		}
+
+		switch key {
+		case "name":
+			af.Name = value
+		case "group":
+			af.Group = true
+		case "key":
+			af.Keyed = true
+			af.MapKey = value
+		default:
+			return fmt.Errorf("di: unknown result tag option %q in tag: %q", key, tag)
+		}
+	}
+
+	return nil
+}
+
+// String returns the name of the field of the result object
+func (rf *resultField) String() string {
+	objType := rf.Result.Fn.Type().Out(0)
+	return fmt.Sprintf("%v.%v", rf.Result, objType.Field(rf.Index).Name)
+}
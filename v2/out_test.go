@@ -0,0 +1,181 @@
+package di
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type resultObject struct {
+	Out
+
+	A       A
+	B       B      `di:"name=other"`
+	Plugin  Plugin `di:"group"`
+	Keyed   Plugin `di:"key=one"`
+	ignored E
+}
+
+func TestOut(t *testing.T) {
+	resultCount := 0
+	newResult := func() resultObject {
+		resultCount += 1
+		return resultObject{
+			A:      &aImpl{resultCount},
+			B:      &bImpl{resultCount, resultCount},
+			Plugin: &pluginImpl{resultCount},
+			Keyed:  &pluginImpl{resultCount},
+		}
+	}
+
+	t.Run("Fields", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{newResult, PerResolve},
+			{NewPlugins, PerDependency},
+			{NewPluginsByKey, PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resolveErr := resolver.Invoke(Params(func(a A, b B, ps Plugins, pk PluginsByKey, p struct {
+			In
+			E E `di:"optional"`
+		}) {
+			b1, _ := b.B()
+			if a.A() != b1 || a.A() != ps.Plugins()[0].Plugin() || a.A() != pk.Plugins()["one"].Plugin() {
+				t.Fatal("expecting every field from the same result object")
+			}
+
+			if p.E != nil {
+				t.Fatal("unexported fields should not be defined")
+			}
+		}, "", "name=other"))
+
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if resultCount != 1 {
+			t.Fatal(resultCount)
+		}
+
+		var a A
+		resolveErr = resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a.A() != 2 || resultCount != 2 {
+			t.Fatal(a.A(), resultCount)
+		}
+	})
+	t.Run("Singleton", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{newResult, Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		var b B
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		resolveErr = resolver.ResolveNamed("other", &b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		b1, _ := b.B()
+		if a.A() != b1 {
+			t.Fatal(a.A(), b1)
+		}
+	})
+	t.Run("GroupOrder", func(t *testing.T) {
+		defs := []*Def{
+			{Group(func() Plugin { return &pluginImpl{1} }), Singleton},
+			{func() resultObject { return resultObject{Plugin: &pluginImpl{2}} }, Singleton},
+			{Group(func() Plugin { return &pluginImpl{3} }), Singleton},
+		}
+
+		for count := 0; count < 20; count += 1 {
+			resolver, err := resolverChildNew(defs)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resolveErr := resolver.Invoke(func(ps []Plugin) {
+				if len(ps) != 3 || ps[0].Plugin() != 1 || ps[1].Plugin() != 2 || ps[2].Plugin() != 3 {
+					t.Fatal("expecting the members in the order they were defined", ps)
+				}
+			})
+
+			if resolveErr != nil {
+				t.Fatal(resolveErr)
+			}
+		}
+	})
+	t.Run("Err", func(t *testing.T) {
+		resultErr := errors.New("result err")
+		resolver, err := resolverChildNew([]*Def{
+			{func() (resultObject, error) { return resultObject{}, resultErr }, Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr == nil || resolveErr.Err != resultErr {
+			t.Fatal(resolveErr)
+		}
+	})
+	t.Run("DefInfo", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{newResult, Singleton}, {newResult, Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		infos := resolver.Definitions()
+		if len(infos) != 5 {
+			t.Fatal(len(infos))
+		}
+
+		for _, info := range infos {
+			if strings.Contains(info.Constructor, "TestOut") == false {
+				t.Fatal(info.Constructor)
+			}
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		type invalidTag struct {
+			Out
+			A A `di:"optional"`
+		}
+
+		invalid := []interface{}{
+			func() invalidTag { return invalidTag{} },
+			Named("one", newResult),
+			Group(newResult),
+		}
+
+		for _, constructor := range invalid {
+			_, err := resolverChildNew([]*Def{{constructor, Singleton}})
+
+			if err == nil {
+				t.Fatal("expecting invalid result object err")
+			}
+		}
+
+		_, err := resolverChildNew([]*Def{{newResult, Singleton}, {NewA, Singleton}})
+		if err == nil {
+			t.Fatal("expecting duplicate definition err")
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package di
+
+// Override defines constructor as a replacement for any earlier definition
+// of the type it returns, instead of the definitions conflicting:
+//
+//	defs := []*di.Def{
+//		{NewSmtpMailer, di.Singleton},
+//		{di.Override(NewFakeMailer), di.Singleton},
+//	}
+//
+// The replacement may have a different constructor and Lifetime than the
+// definition it replaces. A definition is only replaced by an override
+// which comes after it, either later in the same slice of Defs or in a
+// later slice passed to NewResolver. Each replaced definition is recorded
+// in DefInfo.Replaced. An override with nothing to replace is added as a
+// regular definition.
+//
+// Override may be combined with Named or Keyed to replace a named
+// definition or a member of a map. Members of a group and decorators
+// cannot be overridden
+func Override(constructor interface{}) interface{} {
+	return annotate(constructor, func(af *annotatedFn) error {
+		af.Override = true
+		return nil
+	})
+}
+
+// Overrides returns a copy of defs in which each definition overrides any
+// earlier definition of the same type. See Override. Useful for replacing
+// production wiring with test or environment specific wiring:
+//
+//	resolver, err := di.NewResolver(errFn, appDefs, di.Overrides(testDefs))
+func Overrides(defs []*Def) []*Def {
+	overrides := make([]*Def, len(defs))
+
+	for index, def := range defs {
+		overrides[index] = &Def{Override(def.Constructor), def.Lifetime}
+	}
+
+	return overrides
+}
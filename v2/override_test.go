@@ -0,0 +1,200 @@
+package di
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestOverride(t *testing.T) {
+	errFn := func(er *ErrResolve, w http.ResponseWriter, r *http.Request) { panic(er) }
+	newA1 := func() A { return &aImpl{1} }
+	newA2 := func() A { return &aImpl{2} }
+	newA3 := func() A { return &aImpl{3} }
+	newB := func(a A) B { return &bImpl{a.A(), a.A()} }
+
+	t.Run("Replace", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{
+			{newB, PerDependency},
+			{newA1, Singleton},
+			{Override(newA2), PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		a1, a2 := b.B()
+		if a1 != 2 || a2 != 2 {
+			t.Fatal(a1, a2)
+		}
+
+		infos := resolver.Definitions()
+		if len(infos) != 2 || infos[0].Lifetime != PerDependency || len(infos[0].Replaced) != 1 {
+			t.Fatal(infos)
+		}
+
+		replaced := infos[0].Replaced[0]
+		if replaced.Lifetime != Singleton || strings.Contains(replaced.Constructor, "TestOverride") == false {
+			t.Fatal(replaced)
+		}
+
+		if len(infos[1].Replaced) != 0 {
+			t.Fatal(infos[1].Replaced)
+		}
+	})
+	t.Run("Overrides", func(t *testing.T) {
+		resolver, err := NewResolver(errFn,
+			[]*Def{{newA1, Singleton}, {newB, PerDependency}},
+			Overrides([]*Def{{newA2, Singleton}}),
+			Overrides([]*Def{{newA3, Singleton}, {newA3, Singleton}}),
+		)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a.A() != 3 {
+			t.Fatal(a.A())
+		}
+
+		infos := resolver.Definitions()
+		if len(infos[0].Replaced) != 2 || infos[0].Replaced[0].Constructor == infos[0].Replaced[1].Constructor {
+			t.Fatal(infos[0].Replaced)
+		}
+	})
+	t.Run("NothingToReplace", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{Override(newA1), Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a.A() != 1 || len(resolver.Definitions()[0].Replaced) != 0 {
+			t.Fatal(a.A())
+		}
+	})
+	t.Run("NamedAndKeyed", func(t *testing.T) {
+		newPlugin1 := func() Plugin { return &pluginImpl{1} }
+		newPlugin2 := func() Plugin { return &pluginImpl{2} }
+
+		resolver, err := resolverChildNew([]*Def{
+			{Named("a", newA1), Singleton},
+			{newA2, Singleton},
+			{Keyed("one", newPlugin1), Singleton},
+			{Keyed("two", newPlugin1), Singleton},
+			{NewPluginsByKey, PerDependency},
+			{Override(Named("a", newA3)), Singleton},
+			{Override(Keyed("one", newPlugin2)), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a, namedA A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		resolveErr = resolver.ResolveNamed("a", &namedA)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a.A() != 2 || namedA.A() != 3 {
+			t.Fatal(a.A(), namedA.A())
+		}
+
+		var plugins PluginsByKey
+		resolveErr = resolver.Resolve(&plugins)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		ps := plugins.Plugins()
+		if len(ps) != 2 || ps["one"].Plugin() != 2 || ps["two"].Plugin() != 1 {
+			t.Fatal(ps)
+		}
+	})
+	t.Run("Members", func(t *testing.T) {
+		newPlugin1 := func() Plugin { return &pluginImpl{1} }
+		newPlugin2 := func() Plugin { return &pluginImpl{2} }
+
+		resolver, err := resolverChildNew([]*Def{
+			{newA1, Singleton},
+			{Override(newA2), Singleton},
+			{Group(newA3), Singleton},
+			{Keyed("one", newPlugin1), Singleton},
+			{Override(Keyed("one", newPlugin2)), Singleton},
+			{Keyed("two", newPlugin1), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, info := range resolver.Definitions() {
+			isReplacement := info.Group == false && (info.Keyed == false || info.MapKey == "one")
+			if isReplacement && len(info.Replaced) != 1 || isReplacement == false && len(info.Replaced) != 0 {
+				t.Fatal(info.Type, info.MapKey, info.Replaced)
+			}
+		}
+	})
+	t.Run("Decorated", func(t *testing.T) {
+		addTen := func(inner A) A { return &decoratedA{inner, 10} }
+		resolver, err := resolverChildNew([]*Def{
+			{newA1, Singleton},
+			{Decorate(addTen), Singleton},
+			{Override(newA2), Singleton},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var a A
+		resolveErr := resolver.Resolve(&a)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a.A() != 12 {
+			t.Fatal(a.A())
+		}
+	})
+	t.Run("Group", func(t *testing.T) {
+		newPlugin := func() Plugin { return &pluginImpl{1} }
+		_, err := resolverChildNew([]*Def{{Override(Group(newPlugin)), Singleton}})
+
+		if err == nil {
+			t.Fatal("expecting err overriding a group member")
+		}
+	})
+	t.Run("WithoutOverride", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{{newA1, Singleton}, {newA2, Singleton}})
+
+		if err == nil {
+			t.Fatal("expecting err for a conflicting definition")
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// paramTag contains the options of a single parameter tag. See Params
+// for the tag format
+type paramTag struct {
+	// Captive indicates a Singleton may capture the dependency, even if
+	// it has a shorter Lifetime. See Params
+	Captive bool
+
+	// Name is the name of the definition to inject
+	Name string
+
+	// Optional indicates the zero value should be injected if there
+	// is no definition to inject
+	Optional bool
+}
+
+// parseParamTag parses a parameter tag, returning an error if the tag
+// contains an unknown option
+func parseParamTag(tag string) (*paramTag, error) {
+	param := new(paramTag)
+
+	for _, option := range strings.Split(tag, ",") {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			continue
+		}
+
+		key, value := option, ""
+		if index := strings.Index(option, "="); index >= 0 {
+			key, value = option[:index], option[index+1:]
+		}
+
+		switch key {
+		case "name":
+			param.Name = value
+		case "optional":
+			param.Optional = true
+		case "captive":
+			param.Captive = true
+		default:
+			return nil, fmt.Errorf("di: unknown parameter tag option %q in tag: %q", key, tag)
+		}
+	}
+
+	return param, nil
+}
+
+// dependency returns a new dependency of type rtype with the options of
+// the tag. via is the type of the parameter object containing the
+// dependency, if any. If rtype is a provider the dependency is on the
+// type it provides
+func (pt *paramTag) dependency(rtype reflect.Type, via reflect.Type) *dependency {
+	var provider reflect.Type
+	if elemType, isProvider := providerElem(rtype); isProvider {
+		provider, rtype = rtype, elemType
+	}
+
+	return &dependency{
+		Captive:  pt.Captive,
+		Key:      newDepKey(rtype, pt.Name),
+		Optional: pt.Optional,
+		Provider: provider,
+		Via:      via,
+	}
+}
@@ -0,0 +1,46 @@
+package di
+
+import "fmt"
+
+// Params annotates the parameters of fn, a constructor or a func passed
+// to one of the resolver funcs, with tags describing how each parameter
+// should be injected. Tags are matched to parameters by position, and an
+// empty tag leaves the parameter unchanged.
+//
+// A tag is a comma separated list of options:
+//
+//	name=<name>	inject the definition registered under <name>, see Named
+//	optional	inject the zero value of the parameter if it has no definition
+//	captive	allow a Singleton to capture a dependency with a shorter Lifetime
+//
+// Example:
+//
+//	func NewStore(primary, replica DB) Store { ... }
+//	def := &di.Def{di.Params(NewStore, "name=primary", "name=replica"), di.Singleton}
+//
+// An optional parameter only receives the zero value if its own type has
+// no definition. If the definition exists but cannot be resolved, because
+// its constructor returns an error or one of its dependencies is missing,
+// the error is still returned
+func Params(fn interface{}, tags ...string) interface{} {
+	return annotate(fn, func(af *annotatedFn) error {
+		numIn := af.Fn.Type().NumIn()
+		if len(tags) > numIn {
+			return fmt.Errorf("di: Params: %v tags provided for a func with %v parameters", len(tags), numIn)
+		}
+
+		params := make([]*paramTag, len(tags))
+		for index, tag := range tags {
+			param, err := parseParamTag(tag)
+
+			if err != nil {
+				return err
+			}
+
+			params[index] = param
+		}
+
+		af.Params = params
+		return nil
+	})
+}
@@ -0,0 +1,55 @@
+package di
+
+import "reflect"
+
+// providerElem returns the type provided by rtype, and true if rtype is
+// a provider type: func() (Type, error). See the package documentation
+func providerElem(rtype reflect.Type) (reflect.Type, bool) {
+	if rtype.Kind() != reflect.Func || rtype.NumIn() != 0 || rtype.NumOut() != 2 || rtype.IsVariadic() {
+		return nil, false
+	}
+
+	if rtype.Out(1) != errorType || rtype.Out(0).Implements(errType) {
+		return nil, false
+	}
+
+	return rtype.Out(0), true
+}
+
+// isCallback returns true if param has the signature of a provider, but
+// the type it would provide has no definition. The parameter is then left
+// for the caller of a curried func to supply, as it is most likely a
+// callback rather than a provider. An optional provider is always a
+// provider
+func (r *resolverChild) isCallback(param *injectedParam) bool {
+	if param.Fields != nil || param.Deps[0].Provider == nil || param.Deps[0].Optional {
+		return false
+	}
+
+	key := param.Deps[0].Key
+	_, hasDef := r.parent.allDeps[key]
+	return hasDef == false && isBuiltin(key) == false
+}
+
+// provider returns a provider func for dep which resolves the dependency
+// from this resolver when it is called. frame is the frame of the
+// dependent, which is kept to detect a provider which resolves the
+// dependent while it is being constructed. See resolveUsingCache for isRoot
+func (r *resolverChild) provider(depChain []reflect.Type, frame *resolveFrame, dep *dependency, isRoot bool) reflect.Value {
+	depChain = append([]reflect.Type{}, depChain...)
+
+	return reflect.MakeFunc(dep.Provider, func([]reflect.Value) []reflect.Value {
+		var err error
+		value, resolveErr := r.resolveUsingCache(depChain, frame, dep.Key, isRoot)
+
+		if resolveErr != nil {
+			value = reflect.Zero(dep.Key.Type)
+
+			if dep.Optional == false || isDefMissing(resolveErr, dep.Key, depChain) == false {
+				err = resolveErr
+			}
+		}
+
+		return []reflect.Value{value, reflect.ValueOf(&err).Elem()}
+	})
+}
@@ -0,0 +1,195 @@
+package di
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type providerB struct {
+	a func() (A, error)
+}
+
+func (pb *providerB) B() (int, int) {
+	a, err := pb.a()
+
+	if err != nil || a == nil {
+		return -1, -1
+	}
+
+	return a.A(), a.A()
+}
+
+func TestProvider(t *testing.T) {
+	newB := func(a func() (A, error)) B { return &providerB{a} }
+
+	t.Run("Lazy", func(t *testing.T) {
+		numCalls := 0
+		newA := func() A {
+			numCalls += 1
+			return &aImpl{1}
+		}
+
+		resolver, err := resolverChildNew([]*Def{{newA, Singleton}, {newB, PerDependency}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if numCalls != 0 {
+			t.Fatal("expecting A to be constructed on first use", numCalls)
+		}
+
+		a1, a2 := b.B()
+		if a1 != 1 || a2 != 1 || numCalls != 1 {
+			t.Fatal(a1, a2, numCalls)
+		}
+
+		infos := resolver.Definitions()
+		if infos[1].DependsOn[0] != "func() (di.A, error)" {
+			t.Fatal(infos[1].DependsOn)
+		}
+	})
+	t.Run("Cycle", func(t *testing.T) {
+		newA := func(B) A { return &aImpl{2} }
+		resolver, err := resolverChildNew([]*Def{{newA, Singleton}, {newB, Singleton}})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a1, _ := b.B(); a1 != 2 {
+			t.Fatal(a1)
+		}
+	})
+	t.Run("Missing", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{newB, PerDependency}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		_, err = b.(*providerB).a()
+		if err == nil || strings.Contains(err.Error(), "definition missing") == false {
+			t.Fatal(err)
+		}
+	})
+	t.Run("Reentry", func(t *testing.T) {
+		var providerErr error
+		newA := func(b func() (B, error)) (A, error) {
+			_, providerErr = b()
+			return &aImpl{5}, providerErr
+		}
+
+		resolver, err := resolverChildNew([]*Def{{newA, Singleton}, {func(A) B { return nil }, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errs := make(chan *ErrResolve, 1)
+		go func() {
+			var a A
+			errs <- resolver.Resolve(&a)
+		}()
+
+		select {
+		case resolveErr := <-errs:
+			if resolveErr == nil || resolveErr.Err != providerErr {
+				t.Fatal(resolveErr)
+			}
+
+			cycleErr, isCycleErr := providerErr.(*ErrResolve).Err.(*ErrCycle)
+			if isCycleErr == false || len(cycleErr.Cycles[0]) != 3 || cycleErr.Cycles[0][1].Type != bType {
+				t.Fatal(providerErr)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("expecting the re-entered singleton to return an err")
+		}
+
+		var b B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr == nil {
+			t.Fatal("expecting the err constructing A")
+		}
+	})
+	t.Run("Tagged", func(t *testing.T) {
+		newA := func() A { return &aImpl{3} }
+		resolver, err := resolverChildNew([]*Def{
+			{Named("three", newA), Singleton},
+			{Params(newB, "name=three"), PerDependency},
+			{Named("optional", Params(newB, "optional")), PerDependency},
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var b, optionalB B
+		resolveErr := resolver.Resolve(&b)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		resolveErr = resolver.ResolveNamed("optional", &optionalB)
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		if a1, _ := b.B(); a1 != 3 {
+			t.Fatal(a1)
+		}
+
+		a, err := optionalB.(*providerB).a()
+		if a != nil || err != nil {
+			t.Fatal(a, err)
+		}
+	})
+	t.Run("Curry", func(t *testing.T) {
+		resolver, err := resolverChildNew([]*Def{{func() A { return &aImpl{4} }, Singleton}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		curried, resolveErr := resolver.Curry(func(a A, load func() (string, error), provideA func() (A, error)) string {
+			value, _ := load()
+			providedA, _ := provideA()
+			return value + strconv.Itoa(a.A()+providedA.A())
+		})
+		if resolveErr != nil {
+			t.Fatal(resolveErr)
+		}
+
+		curriedFn, isCurriedFn := curried.(func(func() (string, error)) string)
+		if isCurriedFn == false {
+			t.Fatal("expecting the callback to be left for the caller", curried)
+		}
+
+		if value := curriedFn(func() (string, error) { return "a", nil }); value != "a8" {
+			t.Fatal(value)
+		}
+	})
+	t.Run("Define", func(t *testing.T) {
+		_, err := resolverChildNew([]*Def{{func() func() (A, error) { return nil }, Singleton}})
+
+		if err == nil {
+			t.Fatal("expecting err defining a provider type")
+		}
+	})
+}
@@ -1,7 +1,5 @@
 package di
 
-import "reflect"
-
 // resolverNoCache is an instance of resolveCache indicating no
 // caching should take place
 var resolverNoCache = newResolveCache()
@@ -9,24 +7,24 @@ var resolverNoCache = newResolveCache()
 // resolveCache is a cache of values instantiated along the
 // dependency chain
 type resolveCache struct {
-	cache map[reflect.Type]*singleton
+	cache map[*depNode]*singleton
 }
 
 func newResolveCache() *resolveCache {
 	return &resolveCache{
-		cache: make(map[reflect.Type]*singleton),
+		cache: make(map[*depNode]*singleton),
 	}
 }
 
-func (rc *resolveCache) Get(rtype reflect.Type) (*singleton, bool) {
-	s, hasValue := rc.cache[rtype]
+func (rc *resolveCache) Get(node *depNode) (*singleton, bool) {
+	s, hasValue := rc.cache[node]
 	return s, hasValue
 }
 
-func (rc *resolveCache) Set(rtype reflect.Type, value *singleton) {
+func (rc *resolveCache) Set(node *depNode, value *singleton) {
 	if rc == resolverNoCache {
 		return
 	}
 
-	rc.cache[rtype] = value
+	rc.cache[node] = value
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"sync/atomic"
 )
 
 // errorType is typeof(error)
@@ -20,7 +21,7 @@ var iresolverType = reflect.TypeOf((*IResolver)(nil)).Elem()
 type resolverChild struct {
 	parent     *resolverParent
 	closables  []IHttpClosable
-	perDep     map[reflect.Type]*depNode
+	perDep     map[depKey]*depNode
 	perHttp    *resolveCache
 	perResolve *resolveCache
 }
@@ -36,8 +37,6 @@ func newResolverChild(c *resolverParent) *resolverChild {
 		perResolve: newResolveCache(),
 	}
 
-	resolver.perResolve.Set(iresolverType, newSingletonValue(reflect.ValueOf(resolver)))
-
 	return resolver
 }
 
@@ -47,20 +46,26 @@ func newResolverChild(c *resolverParent) *resolverChild {
 func newHttpResolverChild(c *resolverParent, w http.ResponseWriter, r *http.Request) *resolverChild {
 	resolver := newResolverChild(c)
 
-	resolver.perHttp.Set(requestType, newSingletonValue(reflect.ValueOf(r)))
-	resolver.perHttp.Set(responseWriterType, newSingletonValue(reflect.ValueOf(w)))
+	resolver.perHttp.Set(requestNode, newSingletonValue(reflect.ValueOf(r)))
+	resolver.perHttp.Set(responseWriterNode, newSingletonValue(reflect.ValueOf(w)))
 
 	return resolver
 }
 
 func (r *resolverChild) Curry(fn interface{}) (interface{}, *ErrResolve) {
-	fnValue := reflect.ValueOf(fn)
-	err := verifyFn(fnValue)
+	annotated, err := newAnnotatedFn(fn)
 
 	if err != nil {
 		return nil, newErrResolve(nil, err, reflect.TypeOf(fn))
 	}
 
+	inj, err := newInjection(annotated)
+
+	if err != nil {
+		return nil, newErrResolve(nil, err, annotated.Fn.Type())
+	}
+
+	fnValue := annotated.Fn
 	fnType := fnValue.Type()
 	numIn := fnType.NumIn()
 	isVariadic := fnType.IsVariadic()
@@ -68,20 +73,20 @@ func (r *resolverChild) Curry(fn interface{}) (interface{}, *ErrResolve) {
 	callTypes := make([]reflect.Type, 0, numIn)
 	inVals := make([]reflect.Value, numIn)
 
-	for index := 0; index < numIn; index += 1 {
-		inType := fnType.In(index)
+	for index, param := range inj.Params {
+		inType := param.Type
 
-		if index == numIn-1 && isVariadic {
+		if index == numIn-1 && isVariadic || r.isCallback(param) {
 			callTypes = append(callTypes, inType)
 			continue
 		}
 
-		value, err := r.resolveUsingCache(nil, inType)
+		values, err := r.resolveDeps(nil, nil, param.Deps, true)
 
 		if err != nil {
 			_, isErrDefMissing := err.Err.(*ErrDefMissing)
 
-			if isErrDefMissing {
+			if isErrDefMissing && param.Fields == nil {
 				callTypes = append(callTypes, inType)
 				continue
 			}
@@ -90,7 +95,7 @@ func (r *resolverChild) Curry(fn interface{}) (interface{}, *ErrResolve) {
 		}
 
 		knowns[index] = true
-		inVals[index] = value
+		inVals[index] = param.Value(values)
 	}
 
 	numOut := fnType.NumOut()
@@ -152,17 +157,25 @@ func (r *resolverChild) Invoke(fn interface{}) *ErrResolve {
 }
 
 func (r *resolverChild) Resolve(ptrToIface interface{}) *ErrResolve {
+	return r.ResolveNamed("", ptrToIface)
+}
+
+func (r *resolverChild) ResolveNamed(name string, ptrToIface interface{}) *ErrResolve {
 	ptrValue := reflect.ValueOf(ptrToIface)
 	if ptrValue.Kind() != reflect.Ptr {
-		return newErrResolve(nil, fmt.Errorf("di: ptrToIFace must be a *Interface type: %v", ptrValue.Type()), ptrValue.Type())
+		return newErrResolve(nil, fmt.Errorf("di: ptrToIFace must be a pointer type: %v", ptrValue.Type()), ptrValue.Type())
 	}
 
 	ifaceType := ptrValue.Type().Elem()
-	if ifaceType.Kind() != reflect.Interface {
+	if r.parent.isClosed() {
+		return newErrResolve(nil, ErrClosed, ifaceType)
+	}
+
+	if r.parent.options.Strict && ifaceType.Kind() != reflect.Interface {
 		return newErrResolve(nil, fmt.Errorf("di: ptrToIFace must be a *Interface type: %v", ptrValue.Type()), ptrValue.Type())
 	}
 
-	value, err := r.resolveUsingCache(nil, ifaceType)
+	value, err := r.resolveUsingCache(nil, nil, newDepKey(ifaceType, name), true)
 
 	if err != nil {
 		return err
@@ -187,39 +200,120 @@ func (r *resolverChild) lifetimeToCache(l Lifetime) *resolveCache {
 	return resolverNoCache
 }
 
-// resolveUsingCache attempts to resolve a value for a type using this
+// resolveUsingCache attempts to resolve a value for a key using this
 // resolver's cache. ErrDefMissing is returned if there is no
-// definition in this resolver for the specified type
-func (r *resolverChild) resolveUsingCache(depChain []reflect.Type, rtype reflect.Type) (reflect.Value, *ErrResolve) {
-	if rtype == iresolverType {
-		return reflect.ValueOf(r), nil
+// definition in this resolver for the specified key. isRoot is true if
+// the key is resolved for the caller of the resolver rather than for a
+// definition, in which case the definition must be exported by its module
+func (r *resolverChild) resolveUsingCache(depChain []reflect.Type, frame *resolveFrame, key depKey, isRoot bool) (reflect.Value, *ErrResolve) {
+	rtype := key.Type
+
+	if key.Name == "" {
+		switch rtype {
+		case iresolverType:
+			return reflect.ValueOf(r), nil
+		case requestType:
+			return r.resolveHttpValue(depChain, requestNode)
+		case responseWriterType:
+			return r.resolveHttpValue(depChain, responseWriterNode)
+		}
+	}
+
+	dep, hasDep := r.parent.allDeps[key]
+	if hasDep == false {
+		missingErr := newErrDefMissing(key)
+		missingErr.Excluded = r.parent.excluded[key]
+		return reflect.Value{}, newErrResolve(depChain, missingErr, rtype)
+	}
+
+	if isRoot {
+		err := verifyVisible(nil, "the resolver", dep)
+
+		if err != nil {
+			return reflect.Value{}, newErrResolve(depChain, err, rtype)
+		}
+	}
+
+	return r.resolveNode(depChain, frame, dep)
+}
+
+// resolveHttpValue returns the value of one of the http request types
+// supplied to this resolver. ErrDefMissing is returned if this resolver
+// was not created for an http request
+func (r *resolverChild) resolveHttpValue(depChain []reflect.Type, node *depNode) (reflect.Value, *ErrResolve) {
+	httpValue, hasValue := r.perHttp.Get(node)
+
+	if hasValue == false {
+		return reflect.Value{}, newErrResolve(depChain, newErrDefMissing(node.Key), node.Type)
+	}
+
+	value, hasValue := httpValue.Value()
+	if hasValue == false {
+		return reflect.Value{}, newErrResolve(depChain, newErrDefMissing(node.Key), node.Type)
 	}
 
-	if rtype == requestType || rtype == responseWriterType {
-		httpValue, hasValue := r.perHttp.Get(rtype)
+	return value, nil
+}
+
+// resolveFrame is a node which is being resolved on a resolve chain.
+// Providers keep the frames they were created on, so a frame is marked
+// done once the value of its node has been resolved
+type resolveFrame struct {
+	done   int32
+	node   *depNode
+	parent *resolveFrame
+}
+
+// cycle returns the path from the frame of node back to node if node is
+// still being resolved on this chain, otherwise nil
+func (f *resolveFrame) cycle(node *depNode) []*CycleDef {
+	path := []*CycleDef{newCycleDef(node)}
 
-		if hasValue == false {
-			return reflect.Value{}, newErrResolve(depChain, newErrDefMissing(rtype), rtype)
+	for frame := f; frame != nil; frame = frame.parent {
+		if atomic.LoadInt32(&frame.done) == 1 {
+			continue
 		}
 
-		value, hasValue := httpValue.Value()
-		if hasValue == false {
-			return reflect.Value{}, newErrResolve(depChain, newErrDefMissing(rtype), rtype)
+		path = append(path, newCycleDef(frame.node))
+		if frame.node != node {
+			continue
 		}
 
-		return value, nil
+		for index := 0; index < len(path)/2; index += 1 {
+			path[index], path[len(path)-1-index] = path[len(path)-1-index], path[index]
+		}
+
+		return path
 	}
 
-	dep, hasDep := r.parent.allDeps[rtype]
-	if hasDep == false {
-		return reflect.Value{}, newErrResolve(depChain, newErrDefMissing(rtype), rtype)
+	return nil
+}
+
+// resolveNode resolves a value for a node, using the cache
+// indicated by the Lifetime of the node. ErrClosed is returned if the
+// resolver has been closed, and an *ErrCycle if a cached node is
+// resolved again by a provider while it is being constructed
+func (r *resolverChild) resolveNode(depChain []reflect.Type, frame *resolveFrame, node *depNode) (reflect.Value, *ErrResolve) {
+	if r.parent.isClosed() {
+		return reflect.Value{}, newErrResolve(depChain, ErrClosed, node.Type)
 	}
 
-	cache := r.lifetimeToCache(dep.Lifetime)
-	cacheValue, hasCacheValue := cache.Get(rtype)
+	cache := r.lifetimeToCache(node.Lifetime)
+	if cache != resolverNoCache {
+		if path := frame.cycle(node); path != nil {
+			return reflect.Value{}, newErrResolve(depChain, &ErrCycle{Cycles: [][]*CycleDef{path}}, node.Type)
+		}
+	}
+
+	cacheValue, hasCacheValue := cache.Get(node)
 	if hasCacheValue == false {
-		cacheValue = newSingleton(dep)
-		cache.Set(rtype, cacheValue)
+		cacheValue = newSingleton(node)
+		cache.Set(node, cacheValue)
+	}
+
+	if node.Lifetime == Singleton {
+		cacheValue.lock.Lock()
+		defer cacheValue.lock.Unlock()
 	}
 
 	value, hasValue := cacheValue.Value()
@@ -227,15 +321,35 @@ func (r *resolverChild) resolveUsingCache(depChain []reflect.Type, rtype reflect
 		return value, nil
 	}
 
-	return r.resolveIgnoringCache(depChain, dep, cacheValue)
+	childFrame := &resolveFrame{node: node, parent: frame}
+	defer atomic.StoreInt32(&childFrame.done, 1)
+
+	return r.resolveIgnoringCache(depChain, childFrame, node, cacheValue)
 }
 
 // resolveIgnoringCache is called on a resolve cache miss. It attempts to
 // resolve the missing type, and set the cache of the type which is
-// missing with the instantiated value
-func (r *resolverChild) resolveIgnoringCache(depChain []reflect.Type, node *depNode, s *singleton) (reflect.Value, *ErrResolve) {
+// missing with the instantiated value. frame is the frame of node
+func (r *resolverChild) resolveIgnoringCache(depChain []reflect.Type, frame *resolveFrame, node *depNode, s *singleton) (reflect.Value, *ErrResolve) {
+	if node.IsGroup() {
+		values := make([]reflect.Value, len(node.Members))
+		childDepChain := append(depChain, node.Type)
+		for index, member := range node.Members {
+			value, err := r.resolveNode(childDepChain, frame, member)
+
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			values[index] = value
+		}
+
+		value, _ := s.SetValue(values, &r.closables)
+		return value, nil
+	}
+
 	if node.IsLeaf() {
-		value, err := s.SetValue([]reflect.Value{}, &r.closables)
+		value, err := s.SetValue(node.Args([]reflect.Value{}), &r.closables)
 
 		if err != nil {
 			return reflect.Value{}, newErrResolve(depChain, err, node.Type)
@@ -244,22 +358,71 @@ func (r *resolverChild) resolveIgnoringCache(depChain []reflect.Type, node *depN
 		return value, nil
 	}
 
-	values := make([]reflect.Value, len(node.DependsOn))
 	childDepChain := append(depChain, node.Type)
-	for index, dep := range node.DependsOn {
-		value, err := r.resolveUsingCache(childDepChain, dep)
+	values, resolveErr := r.resolveDeps(childDepChain, frame, node.DependsOn, false)
+
+	if resolveErr != nil {
+		return reflect.Value{}, resolveErr
+	}
+
+	value, err := s.SetValue(node.Args(values), &r.closables)
+	if err != nil {
+		return reflect.Value{}, newErrResolve(depChain, err, node.Type)
+	}
+
+	return value, nil
+}
+
+// resolveDeps resolves the value of each dependency in deps. If an
+// optional dependency has no definition its zero value is used instead.
+// isRoot is true if deps are the dependencies of the caller of the
+// resolver rather than of a definition. See resolveUsingCache
+func (r *resolverChild) resolveDeps(depChain []reflect.Type, frame *resolveFrame, deps []*dependency, isRoot bool) ([]reflect.Value, *ErrResolve) {
+	values := make([]reflect.Value, len(deps))
+
+	for index, dep := range deps {
+		childDepChain := depChain
+		if dep.Via != nil {
+			childDepChain = append(depChain[:len(depChain):len(depChain)], dep.Via)
+		}
+
+		if dep.Provider != nil {
+			values[index] = r.provider(childDepChain, frame, dep, isRoot)
+			continue
+		}
+
+		var value reflect.Value
+		var err *ErrResolve
+
+		if dep.Node != nil {
+			value, err = r.resolveNode(childDepChain, frame, dep.Node)
+		} else {
+			value, err = r.resolveUsingCache(childDepChain, frame, dep.Key, isRoot)
+		}
 
 		if err != nil {
-			return reflect.Value{}, err
+			if dep.Optional && isDefMissing(err, dep.Key, childDepChain) {
+				values[index] = reflect.Zero(dep.Key.Type)
+				continue
+			}
+
+			return nil, err
 		}
 
 		values[index] = value
 	}
 
-	value, err := s.SetValue(values, &r.closables)
-	if err != nil {
-		return reflect.Value{}, newErrResolve(depChain, err, node.Type)
+	return values, nil
+}
+
+// isDefMissing returns true if err was caused by key itself having no
+// definition, as opposed to one of the dependencies of key
+func isDefMissing(err *ErrResolve, key depKey, depChain []reflect.Type) bool {
+	missingErr, isMissingErr := err.Err.(*ErrDefMissing)
+
+	if isMissingErr == false || len(err.DependencyChain) != len(depChain) {
+		return false
 	}
 
-	return value, nil
+	return missingErr.Type == key.Type && missingErr.Name == key.Name
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"reflect"
+	"sync"
 	"time"
 )
 
@@ -14,15 +15,41 @@ var iloggerType = reflect.TypeOf((*ILogger)(nil)).Elem()
 // dependency definitions, and created new resolverChild
 // types to handle Resolve() requests.
 type resolverParent struct {
-	allDeps    map[reflect.Type]*depNode
-	deps       map[reflect.Type]*depNode
+	allDeps    map[depKey]*depNode
+	deps       map[depKey]*depNode
+	excluded   map[depKey][]string
+	handlers   []*httpHandlerRoot
 	hasLogger  bool
-	perHttp    map[reflect.Type]*depNode
-	perResolve map[reflect.Type]*depNode
+	options    Options
+	perHttp    map[depKey]*depNode
+	perResolve map[depKey]*depNode
 	singletons *resolveCache
 
 	// errFn is used to write out dependency resolution failures
 	errFn func(*ErrResolve, http.ResponseWriter, *http.Request)
+
+	// handlersLock guards handlers, which may be created concurrently
+	handlersLock sync.Mutex
+
+	// closed is 1 once Close has been called. See isClosed
+	closed int32
+
+	// lifecycleLock guards started, the Singletons which were started by
+	// Start, in the order they were started. nil if the resolver is not
+	// started
+	lifecycleLock sync.Mutex
+	started       []*lifecycleHook
+}
+
+// httpHandlerRoot is an http handler created by the resolver, which is a
+// root of the graph written by WriteGraph
+type httpHandlerRoot struct {
+	// Deps are the dependencies of the handler
+	Deps []*dependency
+
+	// Name is the pattern of the handler if it was registered with
+	// SetDefaultServeMux, otherwise the name of the handler func
+	Name string
 }
 
 // NewResolver returns a new instance of IHttpResolver from
@@ -34,7 +61,15 @@ type resolverParent struct {
 // while resolving one of the dependencies when an injected handler is
 // invoked by an http request.
 func NewResolver(errFn func(*ErrResolve, http.ResponseWriter, *http.Request), defs ...[]*Def) (IHttpResolver, error) {
+	return NewResolverWithOptions(errFn, Options{}, defs...)
+}
+
+// NewResolverWithOptions is the same as NewResolver, except that the
+// behavior of the resolver is configured by options
+func NewResolverWithOptions(errFn func(*ErrResolve, http.ResponseWriter, *http.Request), options Options, defs ...[]*Def) (IHttpResolver, error) {
 	defCollection := newDefCollection()
+	defCollection.profiles = options.Profiles
+	defCollection.strict = options.Strict
 	for _, def := range defs {
 		err := defCollection.AddAll(def)
 
@@ -54,38 +89,72 @@ func NewResolver(errFn func(*ErrResolve, http.ResponseWriter, *http.Request), de
 	}
 
 	numDeps := len(allDeps)
-	deps := make(map[reflect.Type]*depNode, numDeps/4)
+	deps := make(map[depKey]*depNode, numDeps/4)
 	hasLogger := false
-	perHttp := make(map[reflect.Type]*depNode, numDeps/4)
-	perResolve := make(map[reflect.Type]*depNode, numDeps/4)
+	perHttp := make(map[depKey]*depNode, numDeps/4)
+	perResolve := make(map[depKey]*depNode, numDeps/4)
 	singletons := newResolveCache()
 
-	for rtype, node := range allDeps {
-		if rtype == iloggerType {
+	for key, node := range allDeps {
+		if key == newDepKey(iloggerType, "") {
 			hasLogger = true
 		}
 
 		switch node.Lifetime {
-		case Singleton:
-			singletons.Set(rtype, newSingleton(node))
 		case PerDependency:
-			deps[rtype] = node
+			deps[key] = node
 		case PerHttpRequest:
-			perHttp[rtype] = node
+			perHttp[key] = node
 		case PerResolve:
-			perResolve[rtype] = node
+			perResolve[key] = node
 		}
 	}
 
-	return &resolverParent{
+	// every Singleton is resolved concurrently through the shared cache,
+	// including group members and the definitions wrapped by decorators,
+	// so the cache must not be written to after this point
+	for _, node := range allNodes(allDeps) {
+		if node.Lifetime == Singleton {
+			setSingleton(singletons, node)
+		}
+	}
+
+	resolver := &resolverParent{
 		allDeps:    allDeps,
 		deps:       deps,
+		excluded:   defCollection.allExcluded(),
 		hasLogger:  hasLogger,
+		options:    options,
 		perHttp:    perHttp,
 		perResolve: perResolve,
 		singletons: singletons,
 		errFn:      errFn,
-	}, nil
+	}
+
+	if options.Validate {
+		err = resolver.validate(options.Roots)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = resolver.loadConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	return resolver, nil
+}
+
+// setSingleton adds the Singleton node to singletons
+func setSingleton(singletons *resolveCache, node *depNode) {
+	if node.Annotated != nil && node.Annotated.IsInstance() {
+		singletons.Set(node, newSingletonValue(node.Annotated.Instance))
+		return
+	}
+
+	singletons.Set(node, newSingleton(node))
 }
 
 func (c *resolverParent) Curry(fn interface{}) (interface{}, *ErrResolve) {
@@ -93,16 +162,43 @@ func (c *resolverParent) Curry(fn interface{}) (interface{}, *ErrResolve) {
 	return resolver.Curry(fn)
 }
 
+func (c *resolverParent) Definitions() []*DefInfo {
+	return newDefInfos(c.allDeps)
+}
+
 func (c *resolverParent) HttpHandler(fn interface{}) (func(http.ResponseWriter, *http.Request), error) {
-	fnValue := reflect.ValueOf(fn)
-	err := verifyFn(fnValue)
+	return c.httpHandler("", fn)
+}
+
+// httpHandler creates a new http request handler from fn, recording it as
+// a root of the resolver graph under name, or under the name of fn if name
+// is empty
+func (c *resolverParent) httpHandler(name string, fn interface{}) (func(http.ResponseWriter, *http.Request), error) {
+	annotated, err := newAnnotatedFn(fn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	inj, err := newInjection(annotated)
 
 	if err != nil {
 		return nil, err
 	}
 
-	fnType := fnValue.Type()
-	numIn := fnType.NumIn()
+	err = c.verifyRootVisible("the resolver", inj.Deps)
+	if err != nil {
+		return nil, err
+	}
+
+	fnValue := annotated.Fn
+	if name == "" {
+		name = funcName(fnValue)
+	}
+
+	c.handlersLock.Lock()
+	c.handlers = append(c.handlers, &httpHandlerRoot{inj.Deps, name})
+	c.handlersLock.Unlock()
 
 	return func(w http.ResponseWriter, r *http.Request) {
 		var epoch time.Time
@@ -111,23 +207,24 @@ func (c *resolverParent) HttpHandler(fn interface{}) (func(http.ResponseWriter,
 			epoch = time.Now()
 		}
 
-		resolver := newHttpResolverChild(c, w, r)
-		values := make([]reflect.Value, numIn)
-
-		for index := range values {
-			value, err := resolver.resolveUsingCache(nil, fnType.In(index))
+		if c.isClosed() {
+			c.errFn(newErrResolve(nil, ErrClosed, fnValue.Type()), w, r)
+			return
+		}
 
-			if err != nil {
-				c.errFn(err, w, r)
-				return
-			}
+		resolver := newHttpResolverChild(c, w, r)
+		values, resolveErr := resolver.resolveDeps(nil, nil, inj.Deps, true)
 
-			values[index] = value
+		if resolveErr != nil {
+			c.errFn(resolveErr, w, r)
+			return
 		}
 
-		for _, closable := range resolver.closables {
-			defer closable.Di_HttpClose()
-		}
+		defer func() {
+			for _, closable := range resolver.closables {
+				closable.Di_HttpClose()
+			}
+		}()
 
 		if c.hasLogger {
 			duration := time.Since(epoch)
@@ -142,7 +239,12 @@ func (c *resolverParent) HttpHandler(fn interface{}) (func(http.ResponseWriter,
 			logger.HttpDuration(duration)
 		}
 
-		fnValue.Call(values)
+		args := inj.Args(values)
+		if fnValue.Type().IsVariadic() {
+			fnValue.CallSlice(args)
+		} else {
+			fnValue.Call(args)
+		}
 	}, nil
 }
 
@@ -156,9 +258,14 @@ func (c *resolverParent) Resolve(ptrToIface interface{}) *ErrResolve {
 	return resolver.Resolve(ptrToIface)
 }
 
+func (c *resolverParent) ResolveNamed(name string, ptrToIface interface{}) *ErrResolve {
+	resolver := newResolverChild(c)
+	return resolver.ResolveNamed(name, ptrToIface)
+}
+
 func (c *resolverParent) SetDefaultServeMux(httpDefs []*HttpDef) error {
 	for _, httpDef := range httpDefs {
-		injectedHandler, err := c.HttpHandler(httpDef.Handler)
+		injectedHandler, err := c.httpHandler(httpDef.Pattern, httpDef.Handler)
 
 		if err != nil {
 			return err
@@ -127,6 +127,25 @@ func TestResolverParent(t *testing.T) {
 				t.Fatal("per http request not closed")
 			}
 		})
+		t.Run("Provider", func(t *testing.T) {
+			errOnLogger = false
+			var closer3 HttpCloser
+			closer = &closer3
+			handlerFn, err := resolver.HttpHandler(func(provider func() (A, error)) {
+				a, err := provider()
+				if err != nil || a == nil {
+					t.Fatal(a, err)
+				}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			handlerFn(w, r)
+			if closer3.isClosed == false {
+				t.Fatal("dependency resolved by a provider not closed")
+			}
+		})
 		t.Run("Err resolving Logger", func(t *testing.T) {
 			errOnLogger = true
 			handlerFn, err := resolver.HttpHandler(handler)
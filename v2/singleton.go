@@ -1,8 +1,14 @@
 package di
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+)
 
 type singleton struct {
+	// lock is held while a Singleton is created, so that concurrent
+	// resolves create it only once
+	lock  sync.Mutex
 	node  *depNode
 	value reflect.Value
 }
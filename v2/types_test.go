@@ -9,6 +9,8 @@ var aCounter = 0
 var aType = reflect.TypeOf((*A)(nil)).Elem()
 var bType = reflect.TypeOf((*B)(nil)).Elem()
 var eType = reflect.TypeOf((*E)(nil)).Elem()
+var pluginSliceType = reflect.TypeOf([]Plugin(nil))
+var paramObjectType = reflect.TypeOf(paramObject{})
 
 type A interface {
 	A() int
@@ -65,3 +67,39 @@ type SubDep interface{}
 type SubDepNotFound interface{}
 
 func NewSubDepNotFound(SubDep) SubDepNotFound { return new(struct{}) }
+
+type Plugin interface {
+	Plugin() int
+}
+
+type pluginImpl struct {
+	id int
+}
+
+func (pi *pluginImpl) Plugin() int { return pi.id }
+
+type Plugins interface {
+	Plugins() []Plugin
+}
+
+type pluginsImpl struct {
+	plugins []Plugin
+}
+
+func (pi *pluginsImpl) Plugins() []Plugin { return pi.plugins }
+
+func NewPlugins(plugins []Plugin) Plugins { return &pluginsImpl{plugins} }
+
+func NewPluginsVariadic(plugins ...Plugin) Plugins { return &pluginsImpl{plugins} }
+
+type PluginsByKey interface {
+	Plugins() map[string]Plugin
+}
+
+type pluginsByKeyImpl struct {
+	plugins map[string]Plugin
+}
+
+func (pi *pluginsByKeyImpl) Plugins() map[string]Plugin { return pi.plugins }
+
+func NewPluginsByKey(plugins map[string]Plugin) PluginsByKey { return &pluginsByKeyImpl{plugins} }
@@ -0,0 +1,102 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ErrValidation is returned by NewResolver when Options.Validate is true
+// and some dependencies have no definition. See Options.Validate
+//
+// Implements the error interface
+type ErrValidation struct {
+	// Errs contains an *ErrResolve for each dependency which has no
+	// definition. The DependencyChain of each error ends with the
+	// definition or root func which has the dependency
+	Errs []*ErrResolve
+}
+
+// Error returns an error string describing every error encountered
+func (ev *ErrValidation) Error() string {
+	msgs := make([]string, len(ev.Errs))
+	for index, err := range ev.Errs {
+		msgs[index] = err.String()
+	}
+
+	return fmt.Sprintf("di: %v unsatisfiable dependencies:\n\t%v", len(ev.Errs), strings.Join(msgs, "\n\t"))
+}
+
+// validate returns an *ErrValidation if any dependency of a definition,
+// or of one of the roots, has no definition. See Options.Validate
+func (c *resolverParent) validate(roots []interface{}) error {
+	errs := make([]*ErrResolve, 0)
+	for _, node := range allNodes(c.allDeps) {
+		depChain := append(dependentChain(c.allDeps, node), node.Type)
+		errs = append(errs, c.unsatisfied(depChain, node.DependsOn)...)
+	}
+
+	for _, root := range roots {
+		annotated, err := newAnnotatedFn(root)
+		if err != nil {
+			return err
+		}
+
+		inj, err := newInjection(annotated)
+		if err != nil {
+			return err
+		}
+
+		err = c.verifyRootVisible(funcName(annotated.Fn), inj.Deps)
+		if err != nil {
+			return err
+		}
+
+		errs = append(errs, c.unsatisfied([]reflect.Type{annotated.Fn.Type()}, inj.Deps)...)
+	}
+
+	if len(errs) > 0 {
+		return &ErrValidation{errs}
+	}
+
+	return nil
+}
+
+// unsatisfied returns an *ErrResolve for each of deps which is not
+// optional and has no definition. depChain is the chain of the func
+// which has the dependencies
+func (c *resolverParent) unsatisfied(depChain []reflect.Type, deps []*dependency) []*ErrResolve {
+	errs := make([]*ErrResolve, 0)
+
+	for _, dep := range deps {
+		if dep.Optional || dep.Node != nil || isBuiltin(dep.Key) {
+			continue
+		}
+
+		if _, hasDep := c.allDeps[dep.Key]; hasDep {
+			continue
+		}
+
+		missingErr := newErrDefMissing(dep.Key)
+		missingErr.Excluded = c.excluded[dep.Key]
+
+		childDepChain := depChain
+		if dep.Via != nil {
+			childDepChain = append(depChain[:len(depChain):len(depChain)], dep.Via)
+		}
+
+		errs = append(errs, newErrResolve(childDepChain, missingErr, dep.Key.Type))
+	}
+
+	return errs
+}
+
+// isBuiltin returns true if key is supplied by the resolver itself
+// instead of by a definition
+func isBuiltin(key depKey) bool {
+	if key.Name != "" {
+		return false
+	}
+
+	return key.Type == iresolverType || key.Type == requestType || key.Type == responseWriterType
+}
@@ -0,0 +1,58 @@
+package di
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	errFn := func(er *ErrResolve, w http.ResponseWriter, r *http.Request) { panic(er) }
+	newB := func(a A, r *http.Request, resolver IResolver) B { return &bImpl{} }
+	newC := func(b B, d D) C { return new(struct{}) }
+	newE := func(C) E { return new(struct{}) }
+	defs := []*Def{
+		{newB, PerHttpRequest},
+		{newC, PerHttpRequest},
+		{newE, PerHttpRequest},
+		{Params(func(a A) D { return nil }, "optional"), PerHttpRequest},
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		_, err := NewResolver(errFn, defs)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+	t.Run("Unsatisfied", func(t *testing.T) {
+		root := func(a A, p Plugin) {}
+		_, err := NewResolverWithOptions(errFn, Options{Validate: true, Roots: []interface{}{root}}, defs)
+
+		validationErr, isValidationErr := err.(*ErrValidation)
+		if isValidationErr == false || len(validationErr.Errs) != 3 {
+			t.Fatal(err)
+		}
+
+		chain := validationErr.Errs[0].DependencyChain
+		if len(chain) != 3 || chain[0] != eType || chain[2] != bType || validationErr.Errs[0].Type != aType {
+			t.Fatal(validationErr.Errs[0])
+		}
+
+		if validationErr.Errs[2].DependencyChain[0] != reflect.TypeOf(root) {
+			t.Fatal(validationErr.Errs[2])
+		}
+
+		if strings.Contains(err.Error(), "3 unsatisfiable dependencies") == false {
+			t.Fatal(err)
+		}
+	})
+	t.Run("Satisfied", func(t *testing.T) {
+		_, err := NewResolverWithOptions(errFn, Options{Validate: true}, append(defs, &Def{NewA, Singleton}))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+}